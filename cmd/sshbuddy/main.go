@@ -30,7 +30,7 @@ func main() {
 	if m, ok := finalModel.(tui.Model); ok {
 		if m.GetSelectedHost() != nil {
 			host := m.GetSelectedHost()
-			fmt.Printf("Connecting to %s@%s...\n", host.User, host.Hostname)
+			fmt.Printf("Connecting to %s...\n", host.Destination())
 			if err := ssh.ExecuteSSH(*host); err != nil {
 				fmt.Printf("Error connecting to host: %v\n", err)
 				os.Exit(1)
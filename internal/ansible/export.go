@@ -0,0 +1,205 @@
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// exportHeader starts every rendered inventory
+const exportHeader = "# Generated by SSHBuddy - one group per tag\n"
+
+// exportHost is a host prepared for rendering
+type exportHost struct {
+	alias string
+	vars  [][2]string // Connection variables in output order
+	tags  []string    // Group names
+}
+
+// prepareExport converts hosts to inventory hosts grouped by tag. Hosts whose
+// alias can't be an inventory host name are skipped with a warning.
+func prepareExport(hosts []models.Host) (ungrouped []exportHost, groups map[string][]exportHost, warnings []string) {
+	sorted := append([]models.Host(nil), hosts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Alias < sorted[j].Alias })
+
+	groups = make(map[string][]exportHost)
+	for _, host := range sorted {
+		if host.Alias == "" || strings.ContainsAny(host.Alias, " \t[]#=") {
+			warnings = append(warnings, fmt.Sprintf("skipping %q: not a valid inventory host name", host.Alias))
+			continue
+		}
+
+		h := exportHost{alias: host.Alias}
+		if host.IsDocker() {
+			h.vars = append(h.vars, [2]string{"ansible_connection", "docker"})
+		}
+		if host.Hostname != "" && host.Hostname != host.Alias {
+			h.vars = append(h.vars, [2]string{"ansible_host", host.Hostname})
+		}
+		if host.User != "" {
+			h.vars = append(h.vars, [2]string{"ansible_user", host.User})
+		}
+		if host.Port != "" && host.Port != "22" {
+			h.vars = append(h.vars, [2]string{"ansible_port", host.Port})
+		}
+		if host.IdentityFile != "" {
+			h.vars = append(h.vars, [2]string{"ansible_ssh_private_key_file", host.IdentityFile})
+		}
+		if host.ProxyJump != "" {
+			h.vars = append(h.vars, [2]string{"ansible_ssh_common_args", "-o ProxyJump=" + host.ProxyJump})
+		}
+
+		seen := make(map[string]bool)
+		for _, tag := range host.Tags {
+			name := groupName(tag)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			h.tags = append(h.tags, name)
+		}
+		sort.Strings(h.tags)
+
+		if len(h.tags) == 0 {
+			ungrouped = append(ungrouped, h)
+			continue
+		}
+		for _, tag := range h.tags {
+			groups[tag] = append(groups[tag], h)
+		}
+	}
+	return ungrouped, groups, warnings
+}
+
+// groupName turns a tag into a valid group name by replacing everything but
+// letters, digits and underscores with underscores
+func groupName(tag string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(tag))
+	if name == groupAll || name == groupUngrouped {
+		return name + "_"
+	}
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// sortedGroupNames returns the group names of an export in order
+func sortedGroupNames(groups map[string][]exportHost) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderINI renders hosts as an INI inventory with one group per tag.
+// Untagged hosts are listed before the first group. Connection variables are
+// written on the first line listing a host. The returned warnings name the
+// hosts that were left out.
+func RenderINI(hosts []models.Host) (string, []string) {
+	ungrouped, groups, warnings := prepareExport(hosts)
+	written := make(map[string]bool)
+
+	writeHost := func(sb *strings.Builder, h exportHost) {
+		sb.WriteString(h.alias)
+		if !written[h.alias] {
+			for _, v := range h.vars {
+				sb.WriteString(fmt.Sprintf(" %s=%s", v[0], iniQuote(v[1])))
+			}
+			written[h.alias] = true
+		}
+		sb.WriteString("\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(exportHeader)
+	if len(ungrouped) > 0 {
+		sb.WriteString("\n")
+		for _, h := range ungrouped {
+			writeHost(&sb, h)
+		}
+	}
+	for _, name := range sortedGroupNames(groups) {
+		sb.WriteString(fmt.Sprintf("\n[%s]\n", name))
+		for _, h := range groups[name] {
+			writeHost(&sb, h)
+		}
+	}
+	return sb.String(), warnings
+}
+
+// RenderYAML renders hosts as a YAML inventory with one child group of "all"
+// per tag. Untagged hosts are listed under "all" itself.
+func RenderYAML(hosts []models.Host) (string, []string) {
+	ungrouped, groups, warnings := prepareExport(hosts)
+	written := make(map[string]bool)
+
+	writeHost := func(sb *strings.Builder, h exportHost, indent string) {
+		sb.WriteString(fmt.Sprintf("%s%s:\n", indent, yamlQuote(h.alias)))
+		if !written[h.alias] {
+			for _, v := range h.vars {
+				sb.WriteString(fmt.Sprintf("%s  %s: %s\n", indent, v[0], yamlQuote(v[1])))
+			}
+		}
+		written[h.alias] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(exportHeader)
+	sb.WriteString("all:\n")
+	if len(ungrouped) > 0 {
+		sb.WriteString("  hosts:\n")
+		for _, h := range ungrouped {
+			writeHost(&sb, h, "    ")
+		}
+	}
+	if len(groups) > 0 {
+		sb.WriteString("  children:\n")
+		for _, name := range sortedGroupNames(groups) {
+			sb.WriteString(fmt.Sprintf("    %s:\n", name))
+			sb.WriteString("      hosts:\n")
+			for _, h := range groups[name] {
+				writeHost(&sb, h, "        ")
+			}
+		}
+	}
+	return sb.String(), warnings
+}
+
+// iniQuote quotes an INI variable value containing whitespace or quotes
+func iniQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t'\"#") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + value + `"`
+}
+
+// yamlQuote quotes a YAML scalar that would otherwise be read differently
+func yamlQuote(value string) string {
+	plain := value != "" &&
+		!strings.ContainsAny(value[:1], "!&*-?|>%@`'\"{}[],#: ") &&
+		!strings.HasSuffix(value, " ") &&
+		!strings.Contains(value, ": ") &&
+		!strings.Contains(value, " #") &&
+		!strings.HasSuffix(value, ":")
+	switch strings.ToLower(value) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off":
+		plain = false
+	}
+	if plain {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,117 @@
+package ansible
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseINI parses an INI inventory. Sections name a group ([web]), its
+// variables ([web:vars]) or its child groups ([web:children]); host lines
+// are a host pattern followed by key=value variables. Hosts listed before
+// the first section are ungrouped.
+func ParseINI(data []byte) (*Inventory, error) {
+	inv := newInventory()
+	section, kind := groupUngrouped, "hosts"
+
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind = line[1:len(line)-1], "hosts"
+			if name, suffix, ok := strings.Cut(section, ":"); ok {
+				section, kind = name, suffix
+			}
+			switch kind {
+			case "hosts", "vars", "children":
+			default:
+				return nil, fmt.Errorf("line %d: unknown section type %q", i+1, kind)
+			}
+			if section == "" {
+				return nil, fmt.Errorf("line %d: section needs a group name", i+1)
+			}
+			inv.group(section)
+			continue
+		}
+
+		fields, err := splitINIFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch kind {
+		case "hosts":
+			vars := make(map[string]string)
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected key=value, got %q", i+1, field)
+				}
+				vars[key] = value
+			}
+			if err := inv.addHost(section, fields[0], vars); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key=value", i+1)
+			}
+			values, err := splitINIFields(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			inv.group(section).vars[strings.TrimSpace(key)] = strings.Join(values, " ")
+		case "children":
+			inv.addChild(section, fields[0])
+		}
+	}
+	return inv, nil
+}
+
+// splitINIFields splits a line at whitespace outside quotes, dropping the
+// quotes and anything from an unquoted # on
+func splitINIFields(line string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	var quote byte
+	inField := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inField = true
+		case c == ' ' || c == '\t':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		case c == '#' && !inField:
+			return fields, nil
+		default:
+			current.WriteByte(c)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
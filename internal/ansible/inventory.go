@@ -0,0 +1,481 @@
+// Package ansible reads Ansible inventories as host sources and renders
+// sshbuddy hosts as inventories
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sshbuddy/pkg/models"
+	"strconv"
+	"strings"
+)
+
+// Names of the groups every inventory has
+const (
+	groupAll       = "all"
+	groupUngrouped = "ungrouped"
+)
+
+// group is an inventory group
+type group struct {
+	hosts    []string          // Hosts listed in the group itself
+	vars     map[string]string // Group variables
+	children []string          // Child groups
+}
+
+// Inventory is a parsed Ansible inventory
+type Inventory struct {
+	groups    map[string]*group
+	hostVars  map[string]map[string]string // Host variables, merged over all places a host is listed
+	hostOrder []string                     // Hosts in the order they first appear
+}
+
+// newInventory creates an empty inventory
+func newInventory() *Inventory {
+	inv := &Inventory{
+		groups:   make(map[string]*group),
+		hostVars: make(map[string]map[string]string),
+	}
+	inv.group(groupAll)
+	return inv
+}
+
+// group returns the named group, creating it if needed
+func (inv *Inventory) group(name string) *group {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &group{vars: make(map[string]string)}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+// addHost lists host in a group and merges its variables. Host patterns
+// with ranges such as web[01:03] add every host of the range.
+func (inv *Inventory) addHost(groupName, pattern string, vars map[string]string) error {
+	names, err := expandHostPattern(pattern)
+	if err != nil {
+		return err
+	}
+	g := inv.group(groupName)
+	for _, name := range names {
+		hostVars := make(map[string]string)
+		for key, value := range vars {
+			hostVars[key] = value
+		}
+
+		// "host:port" sets the port, as long as it isn't an IPv6 address
+		if host, port, ok := strings.Cut(name, ":"); ok && !strings.Contains(port, ":") {
+			if _, err := strconv.Atoi(port); err == nil {
+				name = host
+				hostVars["ansible_port"] = port
+			}
+		}
+
+		if _, seen := inv.hostVars[name]; !seen {
+			inv.hostVars[name] = make(map[string]string)
+			inv.hostOrder = append(inv.hostOrder, name)
+		}
+		for key, value := range hostVars {
+			inv.hostVars[name][key] = value
+		}
+		if !containsString(g.hosts, name) {
+			g.hosts = append(g.hosts, name)
+		}
+	}
+	return nil
+}
+
+// addChild makes child a child group of parent
+func (inv *Inventory) addChild(parent, child string) {
+	inv.group(child)
+	g := inv.group(parent)
+	if !containsString(g.children, child) {
+		g.children = append(g.children, child)
+	}
+}
+
+// ParseFile reads an inventory file. Files ending in .yml or .yaml are read
+// as YAML, .json as JSON and everything else as INI.
+func ParseFile(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv *Inventory
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		inv, err = ParseYAML(data)
+	case ".json":
+		inv, err = ParseJSON(data)
+	default:
+		inv, err = ParseINI(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return inv, nil
+}
+
+// ParseYAML parses a YAML inventory
+func ParseYAML(data []byte) (*Inventory, error) {
+	doc, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return inventoryFromTree(doc)
+}
+
+// ParseJSON parses an inventory in the JSON form of the YAML layout
+func ParseJSON(data []byte) (*Inventory, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return inventoryFromTree(doc)
+}
+
+// inventoryFromTree builds an inventory from a decoded YAML or JSON document,
+// which maps group names to their hosts, vars and children
+func inventoryFromTree(doc any) (*Inventory, error) {
+	inv := newInventory()
+	if doc == nil {
+		return inv, nil
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("inventory must map group names to groups")
+	}
+	for _, name := range sortedKeys(root) {
+		if err := inv.loadTreeGroup(name, root[name]); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
+
+// loadTreeGroup loads a group of a YAML or JSON inventory
+func (inv *Inventory) loadTreeGroup(name string, def any) error {
+	g := inv.group(name)
+	if def == nil {
+		return nil
+	}
+	fields, ok := def.(map[string]any)
+	if !ok {
+		return fmt.Errorf("group %s must be a mapping", name)
+	}
+
+	// Unknown keys and values of the wrong type are errors, as the hosts
+	// they were meant to list would otherwise go missing silently
+	for _, key := range sortedKeys(fields) {
+		if key != "hosts" && key != "vars" && key != "children" {
+			return fmt.Errorf("group %s: unexpected key %q (only hosts, vars and children are valid)", name, key)
+		}
+	}
+	hosts, err := treeMapping(fields["hosts"], "group %s: hosts must map host names to their variables", name)
+	if err != nil {
+		return err
+	}
+	for _, host := range sortedKeys(hosts) {
+		vars, err := treeMapping(hosts[host], "group %s: variables of host %s must be a mapping", name, host)
+		if err != nil {
+			return err
+		}
+		if err := inv.addHost(name, host, scalarVars(vars)); err != nil {
+			return fmt.Errorf("group %s: %w", name, err)
+		}
+	}
+	vars, err := treeMapping(fields["vars"], "group %s: vars must be a mapping", name)
+	if err != nil {
+		return err
+	}
+	for key, value := range scalarVars(vars) {
+		g.vars[key] = value
+	}
+	children, err := treeMapping(fields["children"], "group %s: children must map group names to groups", name)
+	if err != nil {
+		return err
+	}
+	for _, child := range sortedKeys(children) {
+		inv.addChild(name, child)
+		if err := inv.loadTreeGroup(child, children[child]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// treeMapping returns value as a mapping, or nil if it is empty. Any other
+// value is an error, formatted from format and args.
+func treeMapping(value any, format string, args ...any) (map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	mapping, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf(format, args...)
+	}
+	return mapping, nil
+}
+
+// Hosts resolves the inventory into sshbuddy hosts. Variables are applied
+// like Ansible does: "all" first, then parent groups before their children
+// (groups at the same depth by name), then host variables. Every group a
+// host belongs to, directly or through its children, becomes a tag, except
+// "all" and "ungrouped". Hosts using a non-SSH connection are left out.
+func (inv *Inventory) Hosts() []models.Host {
+	parents := make(map[string][]string)
+	for name, g := range inv.groups {
+		for _, child := range g.children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+
+	depths := make(map[string]int)
+	var depth func(name string, visiting map[string]bool) int
+	depth = func(name string, visiting map[string]bool) int {
+		if name == groupAll {
+			return 0
+		}
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return 1 // Cyclic children; Ansible rejects these
+		}
+		visiting[name] = true
+		d := 1
+		for _, parent := range parents[name] {
+			d = max(d, depth(parent, visiting)+1)
+		}
+		delete(visiting, name)
+		depths[name] = d
+		return d
+	}
+
+	memberOf := make(map[string][]string)
+	for name, g := range inv.groups {
+		for _, host := range g.hosts {
+			memberOf[host] = append(memberOf[host], name)
+		}
+	}
+
+	var hosts []models.Host
+	for _, alias := range inv.hostOrder {
+		// Collect the host's groups and their ancestors
+		groups := map[string]bool{groupAll: true}
+		queue := append([]string(nil), memberOf[alias]...)
+		if len(queue) == 0 || (len(queue) == 1 && queue[0] == groupAll) {
+			queue = append(queue, groupUngrouped)
+		}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if groups[name] && name != groupAll {
+				continue
+			}
+			groups[name] = true
+			queue = append(queue, parents[name]...)
+		}
+
+		ordered := make([]string, 0, len(groups))
+		for name := range groups {
+			ordered = append(ordered, name)
+		}
+		sort.Slice(ordered, func(i, j int) bool {
+			di, dj := depth(ordered[i], map[string]bool{}), depth(ordered[j], map[string]bool{})
+			if di != dj {
+				return di < dj
+			}
+			return ordered[i] < ordered[j]
+		})
+
+		vars := make(map[string]string)
+		var tags []string
+		for _, name := range ordered {
+			if g, ok := inv.groups[name]; ok {
+				for key, value := range g.vars {
+					vars[key] = value
+				}
+			}
+			if name != groupAll && name != groupUngrouped {
+				tags = append(tags, name)
+			}
+		}
+		for key, value := range inv.hostVars[alias] {
+			vars[key] = value
+		}
+
+		if host, ok := hostFromVars(alias, vars); ok {
+			host.Tags = tags
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// hostFromVars maps the connection variables of an inventory host. Hosts
+// using the docker connection become docker hosts; other connections than
+// ssh are skipped. Values using Jinja templates can't be evaluated and are
+// ignored.
+func hostFromVars(alias string, vars map[string]string) (models.Host, bool) {
+	lookup := func(keys ...string) string {
+		for _, key := range keys {
+			if value := vars[key]; value != "" && !strings.Contains(value, "{{") {
+				return value
+			}
+		}
+		return ""
+	}
+
+	switch lookup("ansible_connection") {
+	case "", "ssh", "smart", "paramiko", "paramiko_ssh":
+	case "docker", "community.docker.docker":
+		host := models.Host{
+			Alias:       alias,
+			Hostname:    lookup("ansible_host", "ansible_docker_host"),
+			User:        lookup("ansible_user", "ansible_docker_user"),
+			ConnectMode: models.ConnectModeDocker,
+		}
+		if host.Hostname == "" {
+			host.Hostname = alias
+		}
+		return host, true
+	default:
+		return models.Host{}, false
+	}
+
+	host := models.Host{
+		Alias:        alias,
+		Hostname:     lookup("ansible_host", "ansible_ssh_host"),
+		User:         lookup("ansible_user", "ansible_ssh_user"),
+		Port:         lookup("ansible_port", "ansible_ssh_port"),
+		IdentityFile: lookup("ansible_ssh_private_key_file", "ansible_private_key_file"),
+	}
+	if host.Hostname == "" {
+		host.Hostname = alias
+	}
+	host.ProxyJump = proxyJumpArg(lookup("ansible_ssh_common_args", "ansible_ssh_extra_args"))
+	return host, true
+}
+
+// proxyJumpArg extracts the jump host from SSH arguments given as
+// "-o ProxyJump=host" or "-J host"
+func proxyJumpArg(args string) string {
+	fields := strings.Fields(args)
+	for i, field := range fields {
+		switch {
+		case field == "-J" && i+1 < len(fields):
+			return fields[i+1]
+		case field == "-o" && i+1 < len(fields):
+			if value, ok := strings.CutPrefix(fields[i+1], "ProxyJump="); ok {
+				return value
+			}
+		case strings.HasPrefix(field, "-oProxyJump="):
+			return strings.TrimPrefix(field, "-oProxyJump=")
+		}
+	}
+	return ""
+}
+
+// expandHostPattern expands the ranges of a host pattern, e.g. web[01:03]
+// into web01, web02 and web03, or db-[a:c] into db-a, db-b and db-c. A range
+// may have a stride: [0:10:5].
+func expandHostPattern(pattern string) ([]string, error) {
+	start := strings.Index(pattern, "[")
+	if start == -1 {
+		return []string{pattern}, nil
+	}
+	end := strings.Index(pattern[start:], "]")
+	if end == -1 {
+		return nil, fmt.Errorf("invalid host range in %q", pattern)
+	}
+	end += start
+	prefix, spec, suffix := pattern[:start], pattern[start+1:end], pattern[end+1:]
+
+	parts := strings.Split(spec, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("invalid host range in %q", pattern)
+	}
+	stride := 1
+	if len(parts) == 3 {
+		s, err := strconv.Atoi(parts[2])
+		if err != nil || s < 1 {
+			return nil, fmt.Errorf("invalid host range stride in %q", pattern)
+		}
+		stride = s
+	}
+
+	var values []string
+	from, errFrom := strconv.Atoi(parts[0])
+	to, errTo := strconv.Atoi(parts[1])
+	switch {
+	case errFrom == nil && errTo == nil:
+		width := len(parts[0]) // Leading zeros set the width
+		for i := from; i <= to; i += stride {
+			values = append(values, fmt.Sprintf("%0*d", width, i))
+		}
+	case len(parts[0]) == 1 && len(parts[1]) == 1:
+		for c := parts[0][0]; c <= parts[1][0]; c += byte(stride) {
+			values = append(values, string(c))
+			if int(c)+stride > 255 {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid host range in %q", pattern)
+	}
+
+	var names []string
+	for _, value := range values {
+		// The suffix may contain further ranges
+		rest, err := expandHostPattern(suffix)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rest {
+			names = append(names, prefix+value+r)
+		}
+	}
+	return names, nil
+}
+
+// scalarVars converts the scalar values of a YAML or JSON mapping to strings
+func scalarVars(vars map[string]any) map[string]string {
+	result := make(map[string]string, len(vars))
+	for key, value := range vars {
+		switch v := value.(type) {
+		case string:
+			result[key] = v
+		case float64:
+			result[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			result[key] = strconv.FormatBool(v)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a mapping in sorted order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
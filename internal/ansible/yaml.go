@@ -0,0 +1,313 @@
+package ansible
+
+import (
+	"fmt"
+	"strings"
+)
+
+// This file implements the subset of YAML used by inventory files: block
+// mappings and sequences, plain and quoted scalars, simple flow collections
+// ([a, b] and {k: v}), comments and document markers. Scalars are returned as
+// strings, null values as nil, mappings as map[string]any and sequences as
+// []any. Anchors, aliases, tags, merge keys and multi-document files are not
+// supported and reported as errors, so hosts are never dropped silently.
+
+// yamlLine is a significant line of a YAML document
+type yamlLine struct {
+	number int    // 1-based line number, for errors
+	indent int    // Leading spaces
+	text   string // Content without indentation and trailing comment
+}
+
+// parseYAML parses a YAML document into maps, slices, strings and nils
+func parseYAML(data []byte) (any, error) {
+	var lines []yamlLine
+	ended := false // After a "..." document end marker
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		indentation := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		if strings.Contains(indentation, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		text := strings.TrimRight(stripYAMLComment(raw), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" {
+			continue
+		}
+		if trimmed == "---" || trimmed == "..." {
+			if trimmed == "---" && (len(lines) > 0 || ended) {
+				return nil, fmt.Errorf("line %d: multiple documents in one file are not supported", i+1)
+			}
+			ended = ended || trimmed == "..."
+			continue
+		}
+		if ended {
+			return nil, fmt.Errorf("line %d: content after the end of the document", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// stripYAMLComment removes a trailing comment, which starts with a # at the
+// beginning of the line or after whitespace, outside of quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// yamlParser walks the significant lines of a document
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseBlock parses the mapping or sequence whose entries start at indent
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	line := p.lines[p.pos]
+	if line.text == "-" || strings.HasPrefix(line.text, "- ") {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// parseMapping parses "key: value" entries at indent
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	mapping := make(map[string]any)
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		if key == "<<" {
+			return nil, fmt.Errorf("line %d: merge keys (<<) are not supported", line.number)
+		}
+		if _, exists := mapping[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		p.pos++
+
+		value, err := p.parseValue(rest, indent, line.number)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	return mapping, nil
+}
+
+// parseSequence parses "- item" entries at indent
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	var sequence []any
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		isItem := line.text == "-" || strings.HasPrefix(line.text, "- ")
+		if line.indent == indent && !isItem && len(sequence) > 0 {
+			break // The next key after a sequence at its key's indentation
+		}
+		if line.indent > indent || !isItem {
+			return nil, fmt.Errorf("line %d: expected \"- item\"", line.number)
+		}
+
+		item := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		if _, _, isMapping := splitYAMLKey(item); isMapping && !strings.HasPrefix(item, "[") && !strings.HasPrefix(item, "{") {
+			// "- key: value" starts a mapping indented past the dash
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + 2, text: item}
+			value, err := p.parseMapping(indent + 2)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, value)
+			continue
+		}
+
+		p.pos++
+		value, err := p.parseValue(item, indent, line.number)
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, value)
+	}
+	return sequence, nil
+}
+
+// parseValue parses the value after a key or dash: an inline scalar or flow
+// collection, or a nested block on the following, further indented lines
+func (p *yamlParser) parseValue(text string, indent, number int) (any, error) {
+	if text == "" {
+		if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			return p.parseBlock(p.lines[p.pos].indent)
+		}
+		// A sequence may sit at the same indentation as its key
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && strings.HasPrefix(p.lines[p.pos].text, "- ") {
+			return p.parseSequence(indent)
+		}
+		return nil, nil
+	}
+	switch text[0] {
+	case '&', '*':
+		return nil, fmt.Errorf("line %d: anchors and aliases are not supported", number)
+	case '!':
+		return nil, fmt.Errorf("line %d: tags are not supported", number)
+	}
+	if text == "|" || text == ">" || strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return p.parseBlockScalar(text[0] == '>', indent), nil
+	}
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		value, err := parseYAMLFlow(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		return value, nil
+	}
+	return parseYAMLScalar(text), nil
+}
+
+// parseBlockScalar joins the further indented lines of a | or > scalar
+func (p *yamlParser) parseBlockScalar(folded bool, indent int) string {
+	var parts []string
+	for p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		parts = append(parts, p.lines[p.pos].text)
+		p.pos++
+	}
+	if folded {
+		return strings.Join(parts, " ")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// splitYAMLKey splits "key: value" (or "key:") into key and value
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text == "" {
+		return "", "", false
+	}
+	if text[0] == '\'' || text[0] == '"' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end == -1 {
+			return "", "", false
+		}
+		key = text[1 : end+1]
+		after := text[end+2:]
+		if !strings.HasPrefix(after, ":") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(after[1:]), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// parseYAMLScalar unquotes a scalar and maps null values to nil
+func parseYAMLScalar(text string) any {
+	text = strings.TrimSpace(text)
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	}
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n", `\t`, "\t").Replace(text[1 : len(text)-1])
+	}
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return text
+}
+
+// parseYAMLFlow parses a single-line flow sequence or mapping. Nested flow
+// collections are not supported.
+func parseYAMLFlow(text string) (any, error) {
+	open, closer := text[0], byte(']')
+	if open == '{' {
+		closer = '}'
+	}
+	if text[len(text)-1] != closer {
+		return nil, fmt.Errorf("unterminated flow collection %q", text)
+	}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+
+	var items []string
+	if inner != "" {
+		items = splitYAMLFlowItems(inner)
+	}
+	if open == '[' {
+		sequence := make([]any, 0, len(items))
+		for _, item := range items {
+			sequence = append(sequence, parseYAMLScalar(item))
+		}
+		return sequence, nil
+	}
+
+	mapping := make(map[string]any, len(items))
+	for _, item := range items {
+		key, value, ok := splitYAMLKey(strings.TrimSpace(item))
+		if !ok {
+			// "{a, b}" lists keys without values
+			mapping[strings.TrimSpace(item)] = nil
+			continue
+		}
+		mapping[key] = parseYAMLScalar(value)
+	}
+	return mapping, nil
+}
+
+// splitYAMLFlowItems splits flow collection items at commas outside quotes
+func splitYAMLFlowItems(text string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, strings.TrimSpace(text[start:]))
+}
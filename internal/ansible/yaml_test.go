@@ -0,0 +1,192 @@
+package ansible
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want any
+	}{
+		{"empty", "", nil},
+		{"only comments", "# nothing here\n---\n", nil},
+		{
+			"nested mappings",
+			"all:\n  children:\n    web:\n      hosts:\n        web1:\n",
+			map[string]any{"all": map[string]any{"children": map[string]any{"web": map[string]any{"hosts": map[string]any{"web1": nil}}}}},
+		},
+		{
+			"null values",
+			"a:\nb: ~\nc: null\nd: NULL\n",
+			map[string]any{"a": nil, "b": nil, "c": nil, "d": nil},
+		},
+		{
+			"quoted values",
+			"a: \"x # not a comment\"\nb: 'it''s'\nc: \"tab\\there\"\n\"d e\": '2222'\n",
+			map[string]any{"a": "x # not a comment", "b": "it's", "c": "tab\there", "d e": "2222"},
+		},
+		{
+			"comments",
+			"# header\na: 1 # trailing\n  # indented comment\nb: x#y\n",
+			map[string]any{"a": "1", "b": "x#y"},
+		},
+		{
+			"sequences",
+			"a:\n  - one\n  - k: v\n    l: w\nb:\n- two\nc: [x, 'y, z']\nd: {k: v, flag}\n",
+			map[string]any{
+				"a": []any{"one", map[string]any{"k": "v", "l": "w"}},
+				"b": []any{"two"},
+				"c": []any{"x", "y, z"},
+				"d": map[string]any{"k": "v", "flag": nil},
+			},
+		},
+		{
+			"block scalars",
+			"a: |\n  line 1\n  line 2\nb: >\n  folded\n  text\n",
+			map[string]any{"a": "line 1\nline 2", "b": "folded text"},
+		},
+		{
+			"document markers and CRLF",
+			"---\r\na: 1\r\n...\r\n",
+			map[string]any{"a": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"tab indentation", "a:\n\tb: 1\n", "line 2: tabs"},
+		{"anchor", "a: &base\n  b: 1\n", "line 1: anchors and aliases"},
+		{"alias", "a:\n  b: 1\nc: *base\n", "line 3: anchors and aliases"},
+		{"merge key", "a:\n  <<: *base\n", "line 2: merge keys"},
+		{"tag", "a: !vault |\n  secret\n", "line 1: tags"},
+		{"duplicate key", "web:\n  hosts:\n    a:\nweb:\n  hosts:\n    b:\n", "line 4: duplicate key \"web\""},
+		{"multiple documents", "a: 1\n---\nb: 2\n", "line 2: multiple documents"},
+		{"content after the end", "a: 1\n...\nb: 2\n", "line 3: content after the end"},
+		{"missing colon", "all:\n  hosts:\n    web1\n    web2\n", "line 3: expected \"key: value\""},
+		{"bad indentation", "a:\n    b: 1\n  c: 2\n", "line 3: unexpected indentation"},
+		{"unterminated flow", "a: [x, y\n", "line 1: unterminated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseYAMLInventory(t *testing.T) {
+	doc := `# Production
+all:
+  vars:
+    ansible_user: deploy
+  hosts:
+    bastion:
+      ansible_host: 203.0.113.1
+  children:
+    prod:
+      vars:
+        ansible_port: "2222"
+      children:
+        web:
+          hosts:
+            web1:
+            web2:
+              ansible_host: 10.0.0.2   # private address
+              ansible_user: 'www'
+        db:
+          hosts:
+            db1: {ansible_host: 10.0.0.3}
+    containers:
+      hosts:
+        app:
+          ansible_connection: docker
+    windows:
+      hosts:
+        win1:
+          ansible_connection: winrm
+`
+	inv, err := ParseYAML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]models.Host)
+	for _, host := range inv.Hosts() {
+		got[host.Alias] = host
+	}
+	want := map[string]models.Host{
+		"bastion": {Alias: "bastion", Hostname: "203.0.113.1", User: "deploy"},
+		"web1":    {Alias: "web1", Hostname: "web1", User: "deploy", Port: "2222", Tags: []string{"prod", "web"}},
+		"web2":    {Alias: "web2", Hostname: "10.0.0.2", User: "www", Port: "2222", Tags: []string{"prod", "web"}},
+		"db1":     {Alias: "db1", Hostname: "10.0.0.3", User: "deploy", Port: "2222", Tags: []string{"prod", "db"}},
+		"app":     {Alias: "app", Hostname: "app", User: "deploy", ConnectMode: models.ConnectModeDocker, Tags: []string{"containers"}},
+	}
+	for alias, wantHost := range want {
+		host, ok := got[alias]
+		if !ok {
+			t.Errorf("host %s missing", alias)
+			continue
+		}
+		if host.Hostname != wantHost.Hostname || host.User != wantHost.User || host.Port != wantHost.Port ||
+			host.ConnectMode != wantHost.ConnectMode || !reflect.DeepEqual(host.Tags, wantHost.Tags) {
+			t.Errorf("%s = %+v, want %+v", alias, host, wantHost)
+		}
+	}
+	if _, ok := got["win1"]; ok {
+		t.Error("winrm host should be left out")
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d hosts, want %d", len(got), len(want))
+	}
+}
+
+func TestParseYAMLInventoryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"root is a list", "- web1\n", "must map group names"},
+		{"hosts as a list", "web:\n  hosts:\n    - web1\n    - web2\n", "group web: hosts must map host names"},
+		{"host vars as a scalar", "web:\n  hosts:\n    web1: 10.0.0.1\n", "variables of host web1 must be a mapping"},
+		{"misspelled key", "web:\n  host:\n    web1:\n", "group web: unexpected key \"host\""},
+		{"children as a list", "all:\n  children:\n    - web\n", "group all: children must map group names"},
+		{"vars as a list", "all:\n  vars:\n    - a=1\n", "group all: vars must be a mapping"},
+		{"nested error", "all:\n  children:\n    web:\n      hosts: web1\n", "group web: hosts must map host names"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAML([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sshbuddy/internal/ansible"
+	"sshbuddy/internal/config"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// ExportToAnsible exports hosts as an Ansible inventory with one group per
+// tag. Only manual hosts are exported unless allSources is set. The
+// inventory is INI unless yamlFormat is set or outputFile ends in .yml or
+// .yaml. Without an output file it is printed to stdout; otherwise the file
+// is replaced, keeping a backup of the previous version. With dryRun, a
+// unified diff of the pending change is printed instead of writing.
+func ExportToAnsible(outputFile string, yamlFormat, allSources, dryRun bool) {
+	var cfg *models.Config
+	var err error
+	if allSources {
+		cfg, err = loadConfig()
+	} else {
+		cfg, err = config.LoadConfigRaw()
+	}
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(cfg.Hosts) == 0 {
+		if allSources {
+			fmt.Println("No hosts to export")
+		} else {
+			fmt.Println("No manual hosts to export")
+		}
+		return
+	}
+
+	// Expand ~ to home directory
+	if strings.HasPrefix(outputFile, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			outputFile = filepath.Join(homeDir, outputFile[2:])
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(outputFile)) {
+	case ".yml", ".yaml":
+		yamlFormat = true
+	}
+
+	var inventory string
+	var warnings []string
+	if yamlFormat {
+		inventory, warnings = ansible.RenderYAML(cfg.Hosts)
+	} else {
+		inventory, warnings = ansible.RenderINI(cfg.Hosts)
+	}
+	// Warnings go to stderr so they don't end up in a piped inventory
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	exported := len(cfg.Hosts) - len(warnings)
+
+	if outputFile == "" {
+		fmt.Print(inventory)
+		return
+	}
+
+	existing := ""
+	fileExists := false
+	if data, err := os.ReadFile(outputFile); err == nil {
+		existing = string(data)
+		fileExists = true
+	} else if !os.IsNotExist(err) {
+		fmt.Printf("Error reading %s: %v\n", outputFile, err)
+		os.Exit(1)
+	}
+
+	if dryRun {
+		diff := unifiedDiff(outputFile, outputFile, existing, inventory)
+		if diff == "" {
+			fmt.Printf("No changes to %s\n", outputFile)
+		} else {
+			fmt.Print(diff)
+		}
+		return
+	}
+
+	if inventory == existing {
+		fmt.Printf("%s is already up to date\n", outputFile)
+		return
+	}
+
+	if fileExists {
+		// Keep a copy of the previous file next to it
+		backupFile := outputFile + ".bak"
+		if err := os.WriteFile(backupFile, []byte(existing), 0600); err != nil {
+			fmt.Printf("Warning: Could not create backup: %v\n", err)
+		} else {
+			fmt.Printf("Created backup at %s\n", backupFile)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		fmt.Printf("Error creating directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(outputFile, []byte(inventory), 0644); err != nil {
+		fmt.Printf("Error writing to file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Successfully exported %d hosts to %s\n", exported, outputFile)
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"sshbuddy/internal/ssh"
 	"sshbuddy/pkg/models"
 	"strings"
+	"time"
 )
 
 // HandleCLI processes command-line arguments and returns true if handled
@@ -35,31 +37,92 @@ func HandleCLI(args []string, version string) bool {
 		ListHosts()
 		return true
 
+	case "ping", "status":
+		pattern := ""
+		tag := ""
+		format := "table"
+		for i := 2; i < len(args); i++ {
+			switch {
+			case args[i] == "--json":
+				format = "json"
+			case args[i] == "--csv":
+				format = "csv"
+			case args[i] == "--tag" && i+1 < len(args):
+				tag = args[i+1]
+				i++
+			case strings.HasPrefix(args[i], "-"):
+				fmt.Println("Usage: sshbuddy ping [pattern] [--tag <tag>] [--json|--csv]")
+				fmt.Println("\nOptions:")
+				fmt.Println("  [pattern]      Only probe hosts whose alias matches this glob (e.g. 'web*')")
+				fmt.Println("  --tag <tag>    Only probe hosts with this tag")
+				fmt.Println("  --json         Print results as JSON")
+				fmt.Println("  --csv          Print results as CSV")
+				fmt.Println("\nExits with status 1 if any selected host is down.")
+				os.Exit(1)
+			default:
+				pattern = args[i]
+			}
+		}
+		PingHosts(pattern, tag, format)
+		return true
+
+	case "history":
+		alias := ""
+		if len(args) > 2 {
+			alias = args[2]
+		}
+		ShowPingHistory(alias)
+		return true
+
 	case "import":
 		if len(args) < 3 {
-			fmt.Println("Usage: sshbuddy import <source> [options]")
-			fmt.Println("       sshbuddy import termix [--overwrite]")
-			fmt.Println("       sshbuddy import ssh-config [--overwrite]")
-			fmt.Println("\nOptions:")
-			fmt.Println("  --overwrite    Overwrite existing hosts with the same alias")
+			printImportUsage()
 			os.Exit(1)
 		}
 
-		overwrite := false
-		// Check for overwrite flag in any remaining position
+		var opts ImportOptions
+		var files []string
+		// Flags may come in any position after the source
 		for i := 3; i < len(args); i++ {
-			if args[i] == "--overwrite" {
-				overwrite = true
+			switch {
+			case args[i] == "--overwrite":
+				opts.Conflict = ConflictTake
+			case args[i] == "--merge":
+				opts.Conflict = ConflictMerge
+			case args[i] == "--rename-suffix" && i+1 < len(args):
+				opts.Conflict = ConflictRename
+				opts.RenameSuffix = args[i+1]
+				i++
+			case args[i] == "--interactive" || args[i] == "-i":
+				opts.Interactive = true
+			case args[i] == "--dry-run":
+				opts.DryRun = true
+			case strings.HasPrefix(args[i], "-"):
+				printImportUsage()
+				os.Exit(1)
+			default:
+				files = append(files, args[i])
 			}
 		}
+		if opts.Interactive && opts.DryRun {
+			fmt.Println("--interactive and --dry-run can't be combined")
+			os.Exit(1)
+		}
 
-		if args[2] == "termix" {
-			ImportFromTermix(overwrite)
-		} else if args[2] == "ssh-config" {
-			ImportFromSSHConfig(overwrite)
-		} else {
+		switch args[2] {
+		case "termix":
+			ImportFromTermix(opts)
+		case "ssh-config":
+			ImportFromSSHConfig(opts)
+		case "putty", "termius", "mobaxterm", "csv":
+			if len(files) != 1 {
+				fmt.Printf("Usage: sshbuddy import %s <file> [options]\n", args[2])
+				os.Exit(1)
+			}
+			ImportFromFile(args[2], files[0], opts)
+		default:
 			fmt.Printf("Unknown import source: %s\n", args[2])
-			fmt.Println("Supported sources: termix, ssh-config")
+			fmt.Println("Supported sources: termix, ssh-config, putty, termius, mobaxterm, csv")
 			os.Exit(1)
 		}
 		return true
@@ -67,23 +130,39 @@ func HandleCLI(args []string, version string) bool {
 	case "export":
 		if len(args) < 3 {
 			fmt.Println("Usage: sshbuddy export <format> [options]")
-			fmt.Println("       sshbuddy export ssh-config [--stdout] [--file <path>]")
+			fmt.Println("       sshbuddy export ssh-config [--stdout] [--file <path>] [--dry-run]")
+			fmt.Println("       sshbuddy export ansible [--file <path>] [--yaml] [--all] [--dry-run]")
 			fmt.Println("\nOptions:")
 			fmt.Println("  --stdout       Print to stdout instead of writing to file")
-			fmt.Println("  --file <path>  Write to specific file (defaults to ~/.ssh/config)")
+			fmt.Println("  --file <path>  Write to specific file (ssh-config defaults to ~/.ssh/config,")
+			fmt.Println("                 ansible prints to stdout unless a file is given)")
+			fmt.Println("  --dry-run      Show a diff of the changes without writing")
+			fmt.Println("  --yaml         Write a YAML inventory instead of INI (ansible)")
+			fmt.Println("  --all          Export the hosts of all sources, not only manual hosts (ansible)")
 			os.Exit(1)
 		}
 
-		outputFile := "~/.ssh/config" // Default path
+		outputFile := ""
+		fileSet := false
 		toStdout := false
+		dryRun := false
+		yamlFormat := false
+		allSources := false
 
 		for i := 3; i < len(args); i++ {
-			if args[i] == "--stdout" {
+			if args[i] == "--dry-run" {
+				dryRun = true
+			} else if args[i] == "--stdout" {
 				toStdout = true
-				outputFile = ""
 			} else if args[i] == "--file" && i+1 < len(args) {
 				outputFile = args[i+1]
+				fileSet = true
 				toStdout = false
+				i++
+			} else if args[i] == "--yaml" {
+				yamlFormat = true
+			} else if args[i] == "--all" {
+				allSources = true
 			}
 		}
 
@@ -92,10 +171,15 @@ func HandleCLI(args []string, version string) bool {
 		}
 
 		if args[2] == "ssh-config" {
-			ExportToSSHConfig(outputFile)
+			if !fileSet && !toStdout {
+				outputFile = "~/.ssh/config" // Default path
+			}
+			ExportToSSHConfig(outputFile, dryRun)
+		} else if args[2] == "ansible" {
+			ExportToAnsible(outputFile, yamlFormat, allSources, dryRun)
 		} else {
 			fmt.Printf("Unknown export format: %s\n", args[2])
-			fmt.Println("Supported formats: ssh-config")
+			fmt.Println("Supported formats: ssh-config, ansible")
 			os.Exit(1)
 		}
 		return true
@@ -122,9 +206,23 @@ func HandleCLI(args []string, version string) bool {
 	return false
 }
 
+// loadConfig loads the hosts of all sources for a command. Commands can't
+// ask for the Termix login, so when it has expired they go on with the cached
+// Termix hosts, as if Termix were unreachable, and warn on stderr.
+func loadConfig() (*models.Config, error) {
+	cfg, authErr, err := config.LoadConfigNoLogin(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if authErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using cached Termix hosts (log in from the TUI to refresh them)\n", authErr)
+	}
+	return cfg, nil
+}
+
 // ConnectByAlias connects to a host by its alias
 func ConnectByAlias(alias string) {
-	cfg, err := config.LoadConfig()
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
@@ -143,12 +241,12 @@ func ConnectByAlias(alias string) {
 		fmt.Printf("Host with alias '%s' not found\n", alias)
 		fmt.Println("\nAvailable hosts:")
 		for _, host := range cfg.Hosts {
-			fmt.Printf("  - %s (%s@%s)\n", host.Alias, host.User, host.Hostname)
+			fmt.Printf("  - %s (%s)\n", host.Alias, host.Destination())
 		}
 		os.Exit(1)
 	}
 
-	fmt.Printf("Connecting to %s (%s@%s)...\n", targetHost.Alias, targetHost.User, targetHost.Hostname)
+	fmt.Printf("Connecting to %s (%s)...\n", targetHost.Alias, targetHost.Destination())
 	if err := ssh.ExecuteSSH(*targetHost); err != nil {
 		fmt.Printf("Error connecting to host: %v\n", err)
 		os.Exit(1)
@@ -157,7 +255,7 @@ func ConnectByAlias(alias string) {
 
 // ListHosts lists all configured hosts
 func ListHosts() {
-	cfg, err := config.LoadConfig()
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
@@ -168,18 +266,35 @@ func ListHosts() {
 		return
 	}
 
+	// Sources that could not be reached are listed from the offline cache
+	for _, name := range cfg.StaleSourceNames() {
+		fmt.Printf("Warning: %s is unreachable, showing hosts cached at %s (stale)\n",
+			name, cfg.StaleSources[name].Format("2006-01-02 15:04"))
+	}
+
 	fmt.Println("Available hosts:")
 	for _, host := range cfg.Hosts {
 		source := ""
 		if host.Source != "" && host.Source != "manual" {
-			source = fmt.Sprintf(" [%s]", host.Source)
+			if _, stale := cfg.StaleSources[host.Source]; stale {
+				source = fmt.Sprintf(" [%s, stale]", host.Source)
+			} else {
+				source = fmt.Sprintf(" [%s]", host.Source)
+			}
+		}
+		target := host.Destination()
+		if host.Port != "" {
+			target += ":" + host.Port
+		}
+		if host.IsDocker() {
+			target = "docker:" + host.Hostname
 		}
-		fmt.Printf("  %-20s %s@%s:%s%s\n", host.Alias, host.User, host.Hostname, host.Port, source)
+		fmt.Printf("  %-20s %s%s\n", host.Alias, target, source)
 	}
 }
 
 // ImportFromTermix imports hosts from Termix API to local configuration
-func ImportFromTermix(overwrite bool) {
+func ImportFromTermix(opts ImportOptions) {
 	// Load current config
 	cfg, err := config.LoadConfigRaw()
 	if err != nil {
@@ -220,54 +335,11 @@ func ImportFromTermix(overwrite bool) {
 
 	fmt.Printf("Found %d host(s) in Termix\n\n", len(termixHosts))
 
-	// Build map of existing aliases
-	existingAliases := make(map[string]bool)
-	for _, host := range cfg.Hosts {
-		existingAliases[host.Alias] = true
-	}
-
-	// Import hosts
-	imported := 0
-	skipped := 0
-	updated := 0
-
-	for _, host := range termixHosts {
-		// Change source from termix to manual
-		host.Source = "manual"
-
-		if existingAliases[host.Alias] {
-			if overwrite {
-				// Find and replace the existing host
-				for i, existing := range cfg.Hosts {
-					if existing.Alias == host.Alias {
-						cfg.Hosts[i] = host
-						fmt.Printf("✓ Updated: %s (%s@%s)\n", host.Alias, host.User, host.Hostname)
-						updated++
-						break
-					}
-				}
-			} else {
-				fmt.Printf("✗ Skipped: %s (already exists, use --overwrite to replace)\n", host.Alias)
-				skipped++
-			}
-		} else {
-			cfg.Hosts = append(cfg.Hosts, host)
-			fmt.Printf("✓ Imported: %s (%s@%s)\n", host.Alias, host.User, host.Hostname)
-			imported++
-		}
-	}
-
-	// Save configuration
-	if err := config.SaveConfig(cfg); err != nil {
-		fmt.Printf("\nError saving configuration: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Printf("\nImport complete! Imported: %d, Updated: %d, Skipped: %d\n", imported, updated, skipped)
+	importHosts(cfg, termixHosts, opts)
 }
 
 // ImportFromSSHConfig imports hosts from SSH config to local configuration
-func ImportFromSSHConfig(overwrite bool) {
+func ImportFromSSHConfig(opts ImportOptions) {
 	// Load current config (manual hosts)
 	cfg, err := config.LoadConfigRaw()
 	if err != nil {
@@ -275,11 +347,22 @@ func ImportFromSSHConfig(overwrite bool) {
 		os.Exit(1)
 	}
 
-	fmt.Println("Reading SSH config...")
-	sshHosts, err := ssh.LoadHostsFromSSHConfig()
-	if err != nil {
-		fmt.Printf("Error loading SSH config: %v\n", err)
-		os.Exit(1)
+	// Read every configured SSH config file; earlier files win on duplicate aliases
+	var sshHosts []models.Host
+	seenAliases := make(map[string]bool)
+	for _, configPath := range cfg.SSH.Paths() {
+		fmt.Printf("Reading SSH config %s...\n", configPath)
+		hosts, err := ssh.LoadHostsFromSSHConfig(configPath)
+		if err != nil {
+			fmt.Printf("Error loading SSH config: %v\n", err)
+			os.Exit(1)
+		}
+		for _, host := range hosts {
+			if !seenAliases[host.Alias] {
+				seenAliases[host.Alias] = true
+				sshHosts = append(sshHosts, host)
+			}
+		}
 	}
 
 	if len(sshHosts) == 0 {
@@ -289,59 +372,24 @@ func ImportFromSSHConfig(overwrite bool) {
 
 	fmt.Printf("Found %d host(s) in SSH config\n\n", len(sshHosts))
 
-	// Build map of existing aliases
-	existingAliases := make(map[string]bool)
-	for _, host := range cfg.Hosts {
-		existingAliases[host.Alias] = true
-	}
-
-	// Import hosts
-	imported := 0
-	skipped := 0
-	updated := 0
-
-	for _, host := range sshHosts {
-		// Change source to manual
-		host.Source = "manual"
-		host.AvailableIn = []string{"manual"}
-		// Initialize Variant
-		host.Variants = make(map[string]*models.Host)
-		selfCopy := host
-		host.Variants["manual"] = &selfCopy
-
-		if existingAliases[host.Alias] {
-			if overwrite {
-				// Find and replace the existing host
-				for i, existing := range cfg.Hosts {
-					if existing.Alias == host.Alias {
-						cfg.Hosts[i] = host
-						fmt.Printf("✓ Updated: %s (%s@%s)\n", host.Alias, host.User, host.Hostname)
-						updated++
-						break
-					}
-				}
-			} else {
-				fmt.Printf("- Skipped: %s (already exists)\n", host.Alias)
-				skipped++
-			}
-		} else {
-			cfg.Hosts = append(cfg.Hosts, host)
-			fmt.Printf("+ Imported: %s (%s@%s)\n", host.Alias, host.User, host.Hostname)
-			imported++
-		}
-	}
+	importHosts(cfg, sshHosts, opts)
+}
 
-	// Save configuration
-	if err := config.SaveConfig(cfg); err != nil {
-		fmt.Printf("\nError saving configuration: %v\n", err)
-		os.Exit(1)
-	}
+// Markers delimiting the part of an SSH config file that sshbuddy manages
+const (
+	managedBlockBegin = "# BEGIN sshbuddy"
+	managedBlockEnd   = "# END sshbuddy"
+)
 
-	fmt.Printf("\nImport complete! Imported: %d, Updated: %d, Skipped: %d\n", imported, updated, skipped)
-}
+// legacyExportHeader starts files written by earlier versions, which
+// generated the whole file instead of a managed block
+const legacyExportHeader = "# Generated by SSHBuddy"
 
-// ExportToSSHConfig exports local manual hosts to SSH config format
-func ExportToSSHConfig(outputFile string) {
+// ExportToSSHConfig exports local manual hosts to SSH config format. When
+// writing to a file, only the managed block between the BEGIN/END markers is
+// replaced and everything else in the file is preserved. With dryRun, a
+// unified diff of the pending change is printed instead of writing.
+func ExportToSSHConfig(outputFile string, dryRun bool) {
 	// Load current config (manual hosts only)
 	cfg, err := config.LoadConfigRaw()
 	if err != nil {
@@ -349,15 +397,124 @@ func ExportToSSHConfig(outputFile string) {
 		os.Exit(1)
 	}
 
-	if len(cfg.Hosts) == 0 {
+	block, exported := renderManagedBlock(cfg.Hosts)
+	if exported == 0 {
 		fmt.Println("No manual hosts to export")
 		return
 	}
 
+	if outputFile == "" {
+		fmt.Print(block)
+		return
+	}
+
+	// Expand ~ to home directory
+	if strings.HasPrefix(outputFile, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			outputFile = filepath.Join(homeDir, outputFile[2:])
+		}
+	}
+
+	existing := ""
+	fileExists := false
+	if data, err := os.ReadFile(outputFile); err == nil {
+		existing = string(data)
+		fileExists = true
+	} else if !os.IsNotExist(err) {
+		fmt.Printf("Error reading %s: %v\n", outputFile, err)
+		os.Exit(1)
+	}
+
+	updated, err := replaceManagedBlock(existing, block)
+	if err != nil {
+		fmt.Printf("Error updating %s: %v\n", outputFile, err)
+		os.Exit(1)
+	}
+	if isLegacyExport(existing) {
+		// Appending the block would leave the old hosts above it, and ssh
+		// uses the first value it finds
+		fmt.Printf("%s was generated by an older sshbuddy export; replacing it with the managed block\n", outputFile)
+		if _, err := os.Stat(outputFile + ".bak"); err == nil {
+			fmt.Printf("The older export moved your original config to %s.bak; merge its hosts back into %s\n", outputFile, outputFile)
+		}
+		updated = block
+	}
+
+	if dryRun {
+		diff := unifiedDiff(outputFile, outputFile, existing, updated)
+		if diff == "" {
+			fmt.Printf("No changes to %s\n", outputFile)
+		} else {
+			fmt.Print(diff)
+		}
+		return
+	}
+
+	if updated == existing {
+		fmt.Printf("%s is already up to date\n", outputFile)
+		return
+	}
+
+	if fileExists {
+		// Keep a copy of the previous file next to it
+		backupFile, err := writeBackup(outputFile, []byte(existing))
+		if err != nil {
+			fmt.Printf("Warning: Could not create backup: %v\n", err)
+		} else {
+			fmt.Printf("Created backup at %s\n", backupFile)
+		}
+	} else {
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(outputFile), 0700); err != nil {
+			fmt.Printf("Error creating directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := os.WriteFile(outputFile, []byte(updated), 0644); err != nil {
+		fmt.Printf("Error writing to file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Successfully exported %d hosts to %s\n", exported, outputFile)
+}
+
+// writeBackup saves data as path.bak, or as path.bak.<unix time> if that
+// exists already: an existing backup may be the only copy of a config that
+// an older export replaced, so it is never overwritten. It returns the name
+// of the backup.
+func writeBackup(path string, data []byte) (string, error) {
+	backupFile := path + ".bak"
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(backupFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			if _, err := f.Write(data); err != nil {
+				f.Close()
+				return "", err
+			}
+			return backupFile, f.Close()
+		}
+		if !os.IsExist(err) || attempt == 10 {
+			return "", err
+		}
+		backupFile = fmt.Sprintf("%s.bak.%d", path, time.Now().Unix()+int64(attempt))
+	}
+}
+
+// renderManagedBlock renders hosts as SSH config wrapped in the managed block
+// markers and returns it with the number of hosts it contains
+func renderManagedBlock(hosts []models.Host) (string, int) {
+	count := 0
 	var sb strings.Builder
-	sb.WriteString("# Generated by SSHBuddy\n\n")
+	sb.WriteString(managedBlockBegin + "\n")
+	sb.WriteString("# Generated by SSHBuddy - edits inside this block are overwritten on export\n")
 
-	for _, host := range cfg.Hosts {
+	for _, host := range hosts {
+		if host.IsDocker() {
+			continue // Containers are opened with docker exec, not ssh
+		}
+		count++
+		sb.WriteString("\n")
 		sb.WriteString(fmt.Sprintf("Host %s\n", host.Alias))
 		sb.WriteString(fmt.Sprintf("    HostName %s\n", host.Hostname))
 
@@ -377,44 +534,132 @@ func ExportToSSHConfig(outputFile string) {
 			sb.WriteString(fmt.Sprintf("    ProxyJump %s\n", host.ProxyJump))
 		}
 
-		sb.WriteString("\n")
+		for _, spec := range host.LocalForwards {
+			sb.WriteString(fmt.Sprintf("    LocalForward %s\n", spec))
+		}
+
+		for _, spec := range host.RemoteForwards {
+			sb.WriteString(fmt.Sprintf("    RemoteForward %s\n", spec))
+		}
+
+		for _, spec := range host.DynamicForwards {
+			sb.WriteString(fmt.Sprintf("    DynamicForward %s\n", spec))
+		}
+
+		if host.ForwardAgent {
+			sb.WriteString("    ForwardAgent yes\n")
+		}
+
+		if host.ServerAliveInterval != "" {
+			sb.WriteString(fmt.Sprintf("    ServerAliveInterval %s\n", host.ServerAliveInterval))
+		}
+
+		for _, key := range host.SortedOptionKeys() {
+			sb.WriteString(fmt.Sprintf("    %s %s\n", key, host.Options[key]))
+		}
 	}
 
-	output := sb.String()
+	sb.WriteString(managedBlockEnd + "\n")
+	return sb.String(), count
+}
 
-	if outputFile != "" {
-		// Expand ~ to home directory
-		if strings.HasPrefix(outputFile, "~/") {
-			homeDir, err := os.UserHomeDir()
-			if err == nil {
-				outputFile = filepath.Join(homeDir, outputFile[2:])
-			}
+// isLegacyExport reports whether content was generated by an older export,
+// which wrote the whole file without managed block markers
+func isLegacyExport(content string) bool {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	if strings.TrimSpace(firstLine) != legacyExportHeader {
+		return false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == managedBlockBegin {
+			return false
 		}
+	}
+	return true
+}
 
-		// Create backup if file exists
-		if _, err := os.Stat(outputFile); err == nil {
-			backupFile := outputFile + ".bak"
-			if err := os.Rename(outputFile, backupFile); err != nil {
-				fmt.Printf("Warning: Could not create backup: %v\n", err)
-			} else {
-				fmt.Printf("Created backup at %s\n", backupFile)
+// firstHostBlock returns the index of the first Host or Match line, moved up
+// over the comment lines directly above it, or -1 if there is none
+func firstHostBlock(lines []string) int {
+	for i, line := range lines {
+		keyword := strings.ToLower(strings.TrimSpace(line))
+		if end := strings.IndexAny(keyword, " \t="); end != -1 {
+			keyword = keyword[:end]
+		}
+		if keyword != "host" && keyword != "match" {
+			continue
+		}
+		for i > 0 && strings.HasPrefix(strings.TrimSpace(lines[i-1]), "#") {
+			i--
+		}
+		return i
+	}
+	return -1
+}
+
+// replaceManagedBlock swaps the managed block in content for block. If content
+// has none, block is inserted before the first Host or Match block (and the
+// comments right above it): ssh uses the first value it finds, so a trailing
+// "Host *" must not override the exported hosts. All other lines are left
+// untouched.
+func replaceManagedBlock(content, block string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+
+	begin, end := -1, -1
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case managedBlockBegin:
+			if begin == -1 {
+				begin = i
 			}
-		} else {
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(outputFile), 0700); err != nil {
-				fmt.Printf("Error creating directory: %v\n", err)
-				os.Exit(1)
+		case managedBlockEnd:
+			if begin != -1 && end == -1 {
+				end = i
 			}
 		}
+	}
 
-		if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
-			fmt.Printf("Error writing to file: %v\n", err)
-			os.Exit(1)
+	if begin != -1 && end == -1 {
+		return "", fmt.Errorf("found %q without a matching %q", managedBlockBegin, managedBlockEnd)
+	}
+
+	if begin == -1 {
+		if at := firstHostBlock(lines); at != -1 {
+			before := strings.Join(lines[:at], "")
+			return before + block + "\n" + strings.Join(lines[at:], ""), nil
 		}
-		fmt.Printf("Successfully exported %d hosts to %s\n", len(cfg.Hosts), outputFile)
-	} else {
-		fmt.Println(output)
+
+		// No Host blocks - append, separated by a blank line
+		if content == "" {
+			return block, nil
+		}
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if !strings.HasSuffix(content, "\n\n") {
+			content += "\n"
+		}
+		return content + block, nil
 	}
+
+	before := strings.Join(lines[:begin], "")
+	after := strings.Join(lines[end+1:], "")
+	return before + block + after, nil
+}
+
+// printImportUsage prints the usage of the import command
+func printImportUsage() {
+	fmt.Println("Usage: sshbuddy import <source> [options]")
+	fmt.Println("       sshbuddy import termix [options]")
+	fmt.Println("       sshbuddy import ssh-config [options]")
+	fmt.Println("       sshbuddy import <putty|termius|mobaxterm|csv> <file> [options]")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --overwrite              Replace existing hosts with the same alias")
+	fmt.Println("  --merge                  Fill the fields existing hosts don't set")
+	fmt.Println("  --rename-suffix <suffix> Import conflicting hosts as <alias><suffix>")
+	fmt.Println("  -i, --interactive        Ask how to resolve each conflict")
+	fmt.Println("  --dry-run                Show what would change, with a diff of each conflict")
+	fmt.Println("\nExisting hosts are kept when no option resolves a conflict.")
 }
 
 // PrintHelp prints usage information
@@ -426,14 +671,26 @@ func PrintHelp(version string) {
 	fmt.Println("  sshbuddy c <alias>          Connect to host by alias (short)")
 	fmt.Println("  sshbuddy list               List all configured hosts")
 	fmt.Println("  sshbuddy ls                 List all configured hosts (short)")
-	fmt.Println("  sshbuddy import termix [--overwrite]")
-	fmt.Println("  sshbuddy import ssh-config [--overwrite]")
-	fmt.Println("  sshbuddy export ssh-config [--file <path>] [--stdout]")
+	fmt.Println("  sshbuddy ping [pattern]     Check which hosts are reachable (or: status)")
+	fmt.Println("  sshbuddy history [alias]    Show recent ping results and packet loss")
+	fmt.Println("  sshbuddy import termix [import options]")
+	fmt.Println("  sshbuddy import ssh-config [import options]")
+	fmt.Println("  sshbuddy import <putty|termius|mobaxterm|csv> <file> [import options]")
+	fmt.Println("  sshbuddy export ssh-config [--file <path>] [--stdout] [--dry-run]")
+	fmt.Println("  sshbuddy export ansible [--file <path>] [--yaml] [--all] [--dry-run]")
 	fmt.Println("")
 	fmt.Println("Options:")
-	fmt.Println("  --overwrite    Overwrite existing hosts with same alias (for import)")
+	fmt.Println("  --overwrite    Replace existing hosts with the same alias (for import)")
+	fmt.Println("  --merge        Fill the fields existing hosts don't set (for import)")
+	fmt.Println("  --rename-suffix <suffix>  Import conflicting hosts as <alias><suffix>")
+	fmt.Println("  -i, --interactive         Ask how to resolve each conflict (for import)")
 	fmt.Println("  --file <path>  Write export to specific file (default: ~/.ssh/config)")
 	fmt.Println("  --stdout       Print export to stdout instead of file")
+	fmt.Println("  --dry-run      Show a diff of the export, or what an import would change, without writing")
+	fmt.Println("  --yaml         Export a YAML instead of an INI inventory (for export ansible)")
+	fmt.Println("  --all          Export hosts of all sources (for export ansible)")
+	fmt.Println("  --tag <tag>    Only check hosts with this tag (for ping)")
+	fmt.Println("  --json, --csv  Machine-readable output (for ping)")
 	fmt.Println("")
 	fmt.Println("  sshbuddy completion install Auto-install completion for your shell")
 	fmt.Println("  sshbuddy completion <shell> Generate shell completion script")
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestReplaceManagedBlock(t *testing.T) {
+	const block = managedBlockBegin + "\nHost web\n    HostName 10.0.0.1\n" + managedBlockEnd + "\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty file", "", block},
+		{"only global settings", "ServerAliveInterval 60", "ServerAliveInterval 60\n\n" + block},
+		{
+			"before a leading Host *",
+			"Host *\n    User nobody\n    IdentityFile ~/.ssh/other\n",
+			block + "\nHost *\n    User nobody\n    IdentityFile ~/.ssh/other\n",
+		},
+		{
+			"after global settings, with the host's comment",
+			"Include conf.d/*\n\n# Defaults\nHost=*\n    Port 2200\n",
+			"Include conf.d/*\n\n" + block + "\n# Defaults\nHost=*\n    Port 2200\n",
+		},
+		{
+			"before a Match block",
+			"Compression yes\nmatch host *.internal\n    User ops\n",
+			"Compression yes\n" + block + "\nmatch host *.internal\n    User ops\n",
+		},
+		{
+			"existing block is replaced in place",
+			"Host a\n\n" + managedBlockBegin + "\nHost old\n" + managedBlockEnd + "\n\nHost *\n",
+			"Host a\n\n" + block + "\nHost *\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replaceManagedBlock(tt.content, block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := replaceManagedBlock(managedBlockBegin+"\nHost a\n", block); err == nil {
+		t.Error("expected an error for a block without an end marker")
+	}
+}
+
+func TestRenderManagedBlockCountsExportedHosts(t *testing.T) {
+	block, count := renderManagedBlock([]models.Host{
+		{Alias: "web", Hostname: "10.0.0.1", User: "admin"},
+		{Alias: "app", Hostname: "app", ConnectMode: models.ConnectModeDocker},
+		{Alias: "db", Hostname: "10.0.0.2", User: "admin", Port: "2222"},
+	})
+	if count != 2 {
+		t.Errorf("counted %d hosts, want 2", count)
+	}
+	if strings.Contains(block, "Host app") {
+		t.Error("the container was exported")
+	}
+}
+
+func TestWriteBackupKeepsExistingBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+
+	first, err := writeBackup(path, []byte("original"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != path+".bak" {
+		t.Errorf("first backup is %s, want %s.bak", first, path)
+	}
+
+	var names []string
+	for _, content := range []string{"second", "third"} {
+		name, err := writeBackup(path, []byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(name, path+".bak.") {
+			t.Errorf("backup written to %s, want a timestamped name", name)
+		}
+		names = append(names, name)
+	}
+	if names[0] == names[1] {
+		t.Errorf("both backups were written to %s", names[0])
+	}
+
+	if data, _ := os.ReadFile(path + ".bak"); string(data) != "original" {
+		t.Errorf("config.bak was overwritten with %q", data)
+	}
+}
+
+func TestIsLegacyExport(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{legacyExportHeader + "\n# Do not edit\n\nHost web\n", true},
+		{legacyExportHeader + "\n" + managedBlockBegin + "\n" + managedBlockEnd + "\n", false},
+		{"Host web\n" + legacyExportHeader + "\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isLegacyExport(tt.content); got != tt.want {
+			t.Errorf("isLegacyExport(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
@@ -28,7 +28,7 @@ _sshbuddy_completion() {
     cur="${COMP_WORDS[COMP_CWORD]}"
     prev="${COMP_WORDS[COMP_CWORD-1]}"
     cmd="${COMP_WORDS[0]}"
-    commands="connect|c list|ls import export completion help"
+    commands="connect|c list|ls ping|status history import export completion help"
 
     # Complete subcommands and flags
     if [ $COMP_CWORD -eq 1 ]; then
@@ -36,14 +36,14 @@ _sshbuddy_completion() {
         if [[ ${cur} == -* ]]; then
             COMPREPLY=( $(compgen -W "--version --help -v -h" -- ${cur}) )
         else
-            local expanded_commands="connect c list ls import export completion help"
+            local expanded_commands="connect c list ls ping status history import export completion help"
             COMPREPLY=( $(compgen -W "${expanded_commands}" -- ${cur}) )
         fi
         return 0
     fi
 
-    # Complete aliases for connect/c command
-    if [ "${prev}" == "connect" ] || [ "${prev}" == "c" ]; then
+    # Complete aliases for connect/c and history commands
+    if [ "${prev}" == "connect" ] || [ "${prev}" == "c" ] || [ "${prev}" == "history" ]; then
         local IFS=$'\n'
         local aliases=()
         while IFS= read -r line; do
@@ -55,27 +55,45 @@ _sshbuddy_completion() {
         return 0
     fi
 
+    # Complete ping flags
+    if [[ ${COMP_WORDS[1]} == "ping" || ${COMP_WORDS[1]} == "status" ]] && [[ ${cur} == -* ]]; then
+        COMPREPLY=( $(compgen -W "--tag --json --csv" -- ${cur}) )
+        return 0
+    fi
+
     # Complete import sources
     if [ "${prev}" == "import" ]; then
-        COMPREPLY=( $(compgen -W "termix ssh-config" -- ${cur}) )
+        COMPREPLY=( $(compgen -W "termix ssh-config putty termius mobaxterm csv" -- ${cur}) )
         return 0
     fi
     
     # Complete export formats
     if [ "${prev}" == "export" ]; then
-        COMPREPLY=( $(compgen -W "ssh-config" -- ${cur}) )
+        COMPREPLY=( $(compgen -W "ssh-config ansible" -- ${cur}) )
         return 0
     fi
     
     # Complete export flags
     if [[ ${prev} == "ssh-config" && "${COMP_WORDS[COMP_CWORD-2]}" == "export" ]]; then
-        COMPREPLY=( $(compgen -W "--stdout --file" -- ${cur}) )
+        COMPREPLY=( $(compgen -W "--stdout --file --dry-run" -- ${cur}) )
+        return 0
+    fi
+    if [[ ${prev} == "ansible" && "${COMP_WORDS[COMP_CWORD-2]}" == "export" ]]; then
+        COMPREPLY=( $(compgen -W "--file --yaml --all --dry-run" -- ${cur}) )
         return 0
     fi
     
     # Complete import flags
-    if [[ (${prev} == "termix" || ${prev} == "ssh-config") && "${COMP_WORDS[COMP_CWORD-2]}" == "import" ]]; then
-        COMPREPLY=( $(compgen -W "--overwrite" -- ${cur}) )
+    if [[ "${COMP_WORDS[2]}" =~ ^(termix|ssh-config)$ && "${COMP_WORDS[1]}" == "import" ]]; then
+        COMPREPLY=( $(compgen -W "--overwrite --merge --rename-suffix --interactive --dry-run" -- ${cur}) )
+        return 0
+    fi
+    if [[ "${COMP_WORDS[2]}" =~ ^(putty|termius|mobaxterm|csv)$ && "${COMP_WORDS[1]}" == "import" ]]; then
+        if [[ ${cur} == -* ]]; then
+            COMPREPLY=( $(compgen -W "--overwrite --merge --rename-suffix --interactive --dry-run" -- ${cur}) )
+        else
+            COMPREPLY=( $(compgen -f -- ${cur}) )
+        fi
         return 0
     fi
 
@@ -107,6 +125,8 @@ _sshbuddy() {
             commands=(
                 {'connect','c'}':Connect to host by alias'
                 {'list','ls'}':List all configured hosts'
+                {'ping','status'}':Check which hosts are reachable'
+                'history:Show recent ping results'
                 'import:Import hosts from external source'
                 'export:Export hosts to external format'
                 'completion:Generate shell completion script'
@@ -116,7 +136,7 @@ _sshbuddy() {
             ;;
         args)
             case $line[1] in
-                connect|c)
+                connect|c|history)
                     local -a hosts
                     while IFS= read -r line; do
                         # Extract alias: trim leading/trailing spaces, then get text before double-space
@@ -125,11 +145,22 @@ _sshbuddy() {
                     done < <(sshbuddy list 2>/dev/null | tail -n +2)
                     _describe 'host aliases' hosts
                     ;;
+                ping|status)
+                    _arguments \
+                        '--tag[Only check hosts with this tag]:tag:' \
+                        '(--csv)--json[Print results as JSON]' \
+                        '(--json)--csv[Print results as CSV]' \
+                        '1::alias pattern:'
+                    ;;
                 import)
                     local -a sources
                     sources=(
                         'termix:Import from Termix API'
                         'ssh-config:Import from SSH config file'
+                        'putty:Import a PuTTY registry export (.reg)'
+                        'termius:Import a Termius JSON export'
+                        'mobaxterm:Import MobaXterm sessions (.mxtsessions)'
+                        'csv:Import a CSV spreadsheet'
                     )
                     _describe 'source' sources
                     ;;
@@ -137,6 +168,7 @@ _sshbuddy() {
                     local -a formats
                     formats=(
                         'ssh-config:Export to SSH config format'
+                        'ansible:Export as Ansible inventory grouped by tag'
                     )
                     _describe 'format' formats
                     ;;
@@ -172,23 +204,45 @@ complete -c sshbuddy -n "__fish_use_subcommand" -a "connect" -d "Connect to host
 complete -c sshbuddy -n "__fish_use_subcommand" -a "c" -d "Connect to host (or: connect)"
 complete -c sshbuddy -n "__fish_use_subcommand" -a "list" -d "List all hosts (or: ls)"
 complete -c sshbuddy -n "__fish_use_subcommand" -a "ls" -d "List all hosts (or: list)"
+complete -c sshbuddy -n "__fish_use_subcommand" -a "ping" -d "Check which hosts are reachable (or: status)"
+complete -c sshbuddy -n "__fish_use_subcommand" -a "status" -d "Check which hosts are reachable (or: ping)"
+complete -c sshbuddy -n "__fish_use_subcommand" -a "history" -d "Show recent ping results"
 complete -c sshbuddy -n "__fish_use_subcommand" -a "import" -d "Import hosts from external source"
 complete -c sshbuddy -n "__fish_use_subcommand" -a "export" -d "Export hosts to external format"
 complete -c sshbuddy -n "__fish_use_subcommand" -a "completion" -d "Generate shell completion script"
 complete -c sshbuddy -n "__fish_use_subcommand" -a "help" -d "Show help"
 
-# Complete aliases for connect/c command
-complete -c sshbuddy -n "__fish_seen_subcommand_from connect c" -a "(sshbuddy list 2>/dev/null | tail -n +2 | sed 's/^  *//' | sed 's/  .*//' | string escape)"
+# Complete ping flags
+complete -c sshbuddy -n "__fish_seen_subcommand_from ping status" -l tag -r -d "Only check hosts with this tag"
+complete -c sshbuddy -n "__fish_seen_subcommand_from ping status" -l json -d "Print results as JSON"
+complete -c sshbuddy -n "__fish_seen_subcommand_from ping status" -l csv -d "Print results as CSV"
+
+# Complete aliases for connect/c and history commands
+complete -c sshbuddy -n "__fish_seen_subcommand_from connect c history" -a "(sshbuddy list 2>/dev/null | tail -n +2 | sed 's/^  *//' | sed 's/  .*//' | string escape)"
 
 # Import commands
 complete -c sshbuddy -n "__fish_seen_subcommand_from import" -a "termix" -d "Import from Termix API"
 complete -c sshbuddy -n "__fish_seen_subcommand_from import" -a "ssh-config" -d "Import from SSH config file"
-complete -c sshbuddy -n "__fish_seen_subcommand_from import; and __fish_seen_subcommand_from termix ssh-config" -l overwrite -d "Overwrite existing hosts"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import" -a "putty" -d "Import a PuTTY registry export (.reg)"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import" -a "termius" -d "Import a Termius JSON export"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import" -a "mobaxterm" -d "Import MobaXterm sessions (.mxtsessions)"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import" -a "csv" -d "Import a CSV spreadsheet"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import; and __fish_seen_subcommand_from termix ssh-config putty termius mobaxterm csv" -l overwrite -d "Overwrite existing hosts"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import; and __fish_seen_subcommand_from termix ssh-config putty termius mobaxterm csv" -l merge -d "Fill the fields existing hosts don't set"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import; and __fish_seen_subcommand_from termix ssh-config putty termius mobaxterm csv" -l rename-suffix -r -d "Import conflicting hosts under a new alias"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import; and __fish_seen_subcommand_from termix ssh-config putty termius mobaxterm csv" -s i -l interactive -d "Ask how to resolve each conflict"
+complete -c sshbuddy -n "__fish_seen_subcommand_from import; and __fish_seen_subcommand_from termix ssh-config putty termius mobaxterm csv" -l dry-run -d "Show what would change without saving"
 
 # Export commands
 complete -c sshbuddy -n "__fish_seen_subcommand_from export" -a "ssh-config" -d "Export to SSH config format"
 complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ssh-config" -l file -r -d "Write to specific file"
 complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ssh-config" -l stdout -d "Print to stdout"
+complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ssh-config" -l dry-run -d "Show a diff without writing"
+complete -c sshbuddy -n "__fish_seen_subcommand_from export" -a "ansible" -d "Export as Ansible inventory grouped by tag"
+complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ansible" -l file -r -d "Write to specific file"
+complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ansible" -l yaml -d "Write a YAML inventory"
+complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ansible" -l all -d "Export hosts of all sources"
+complete -c sshbuddy -n "__fish_seen_subcommand_from export; and __fish_seen_subcommand_from ansible" -l dry-run -d "Show a diff without writing"
 
 # Complete shell names for completion command
 complete -c sshbuddy -n "__fish_seen_subcommand_from completion" -a "install" -d "Auto-install for current shell"
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sshbuddy/internal/config"
+	"sshbuddy/internal/ssh"
+	"strings"
+	"time"
+)
+
+// ShowPingHistory prints the recorded probe results. Without an alias it
+// summarizes every host; with one it lists that host's individual probes.
+func ShowPingHistory(alias string) {
+	history := config.LoadPingHistory()
+	if len(history) == 0 {
+		fmt.Println("No ping history recorded yet. Open the TUI and press 'p' to ping hosts.")
+		return
+	}
+
+	if alias != "" {
+		showHostHistory(history, alias)
+		return
+	}
+
+	aliases := make([]string, 0, len(history))
+	for a := range history {
+		aliases = append(aliases, a)
+	}
+	sort.Strings(aliases)
+
+	fmt.Printf("  %-20s %7s %6s %22s  %-30s %s\n", "ALIAS", "PROBES", "LOSS", "MIN/AVG/MAX", "HISTORY", "LAST PROBE")
+	for _, a := range aliases {
+		samples := history[a]
+		if len(samples) == 0 {
+			continue
+		}
+		fmt.Printf("  %-20s %7d %5.0f%% %22s  %s %s\n",
+			a,
+			len(samples),
+			ssh.Loss(samples),
+			formatLatencyStats(samples),
+			ssh.Sparkline(samples)+strings.Repeat(" ", ssh.PingHistorySize-len(samples)),
+			formatAge(samples[len(samples)-1].Time),
+		)
+	}
+}
+
+// showHostHistory lists each recorded probe for one host
+func showHostHistory(history ssh.PingHistory, alias string) {
+	// Aliases are matched case-insensitively, like connect
+	var samples []ssh.PingSample
+	for a, s := range history {
+		if strings.EqualFold(a, alias) {
+			alias = a
+			samples = s
+			break
+		}
+	}
+	if len(samples) == 0 {
+		fmt.Printf("No ping history for '%s'\n", alias)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s: %d probes, %.0f%% loss, min/avg/max %s\n",
+		alias, len(samples), ssh.Loss(samples), formatLatencyStats(samples))
+	fmt.Printf("  %s\n\n", ssh.Sparkline(samples))
+
+	for _, sample := range samples {
+		status := "timeout"
+		if sample.OK {
+			status = fmt.Sprintf("%.1fms", sample.LatencyMs)
+		}
+		fmt.Printf("  %s  %s\n", sample.Time.Local().Format("2006-01-02 15:04:05"), status)
+	}
+}
+
+// formatLatencyStats formats the latency range of the successful probes
+func formatLatencyStats(samples []ssh.PingSample) string {
+	minMs, avgMs, maxMs := ssh.LatencyStats(samples)
+	if maxMs == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f/%.1f/%.1fms", minMs, avgMs, maxMs)
+}
+
+// formatAge formats how long ago t was, e.g. "5m ago"
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds ago", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
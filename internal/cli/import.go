@@ -0,0 +1,420 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sshbuddy/internal/config"
+	"sshbuddy/internal/importer"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// Ways to resolve an imported host whose alias is taken by a different host
+const (
+	ConflictKeep   = "keep"   // Keep the existing host and skip the incoming one
+	ConflictTake   = "take"   // Replace the existing host with the incoming one
+	ConflictMerge  = "merge"  // Fill the fields the existing host doesn't set from the incoming one
+	ConflictRename = "rename" // Import the incoming host under a new alias
+)
+
+// DefaultRenameSuffix is appended to the alias of renamed hosts when no
+// suffix is given
+const DefaultRenameSuffix = "-imported"
+
+// ImportOptions controls how imported hosts are saved
+type ImportOptions struct {
+	Conflict     string // How to resolve conflicts, ConflictKeep if empty
+	RenameSuffix string // Suffix of renamed hosts, DefaultRenameSuffix if empty
+	Interactive  bool   // Ask how to resolve each conflict
+	DryRun       bool   // Only print what would change
+}
+
+// importSummary counts what happened to the imported hosts
+type importSummary struct {
+	imported, updated, skipped int
+}
+
+// ImportFromFile imports the sessions of another SSH client's export file
+// (see importer.Formats) as manual hosts
+func ImportFromFile(format, path string, opts ImportOptions) {
+	cfg, err := config.LoadConfigRaw()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Expand ~ to home directory
+	if strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(homeDir, path[2:])
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	result, err := importer.Parse(format, data)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	if len(result.Hosts) == 0 {
+		fmt.Printf("No hosts found in %s\n", path)
+		return
+	}
+	fmt.Printf("Found %d host(s) in %s\n\n", len(result.Hosts), path)
+
+	importHosts(cfg, result.Hosts, opts)
+}
+
+// importHosts adds hosts to the manual hosts of cfg and saves it, printing
+// what happens to each of them (see importPlan.add). Conflicts are resolved
+// as opts says. With opts.DryRun, nothing is saved and the messages say what
+// would happen.
+func importHosts(cfg *models.Config, hosts []models.Host, opts ImportOptions) {
+	currentUser := ""
+	if current, err := user.Current(); err == nil {
+		currentUser = current.Username
+	}
+	plan := newImportPlan(cfg, opts, currentUser)
+	if opts.Interactive {
+		prompt := bufio.NewReader(os.Stdin)
+		plan.askConflict = func(alias string, changes []fieldChange) string {
+			fmt.Printf("~ Conflict: %s\n", alias)
+			printChanges(changes)
+			return askConflict(prompt)
+		}
+		plan.askAlias = func(suggested string) string {
+			return askAlias(prompt, suggested, plan.existing, plan.seen)
+		}
+	}
+
+	for _, host := range hosts {
+		printAction(plan.add(host), opts)
+	}
+
+	summary := plan.summary
+	if opts.DryRun {
+		fmt.Printf("\nDry run, nothing was saved. Would import: %d, update: %d, skip: %d\n", summary.imported, summary.updated, summary.skipped)
+		return
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		fmt.Printf("\nError saving configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nImport complete! Imported: %d, Updated: %d, Skipped: %d\n", summary.imported, summary.updated, summary.skipped)
+}
+
+// What importPlan.add did with a host
+const (
+	actionImport = "import" // Added under its own alias
+	actionRename = "rename" // Added under a new alias
+	actionUpdate = "update" // Replaced the existing host
+	actionMerge  = "merge"  // Filled fields of the existing host
+	actionKeep   = "keep"   // Conflict left unresolved
+	actionSkip   = "skip"   // Not imported, see reason
+)
+
+// importAction is what importPlan.add did with a host
+type importAction struct {
+	kind    string
+	host    models.Host   // The host as imported, with its new alias if renamed
+	reason  string        // Why the host was skipped
+	changes []fieldChange // Differences to the existing host, for conflicts
+	filled  []string      // Fields filled in by a merge
+}
+
+// importPlan adds imported hosts to the manual hosts of a config
+type importPlan struct {
+	cfg         *models.Config
+	conflict    string // How to resolve conflicts without askConflict
+	suffix      string // Suffix of renamed hosts
+	currentUser string // User of hosts that don't set one
+	existing    map[string]int
+	seen        map[string]bool
+	summary     importSummary
+
+	// askConflict, if set, resolves each conflict instead of conflict
+	askConflict func(alias string, changes []fieldChange) string
+	// askAlias, if set, picks the alias of a renamed host from a suggestion
+	askAlias func(suggested string) string
+}
+
+// newImportPlan returns a plan that adds hosts to cfg
+func newImportPlan(cfg *models.Config, opts ImportOptions, currentUser string) *importPlan {
+	plan := &importPlan{
+		cfg:         cfg,
+		conflict:    opts.Conflict,
+		suffix:      opts.RenameSuffix,
+		currentUser: currentUser,
+		existing:    make(map[string]int),
+		seen:        make(map[string]bool),
+	}
+	for i, host := range cfg.Hosts {
+		plan.existing[host.Alias] = i
+	}
+	return plan
+}
+
+// add adds host to the config. Hosts with a new alias are imported and hosts
+// identical to an existing one skipped. A host whose alias is taken by a
+// different host is a conflict, resolved by askConflict or the plan's
+// conflict setting. Hosts without a user get the current user, as ssh would
+// use it; invalid hosts and repeated aliases are skipped.
+func (p *importPlan) add(host models.Host) importAction {
+	host.Source = "manual"
+	host.AvailableIn = nil
+	host.Variants = nil
+	if host.User == "" {
+		host.User = p.currentUser
+	}
+
+	action := p.resolve(host)
+	switch action.kind {
+	case actionImport, actionRename:
+		p.summary.imported++
+	case actionUpdate, actionMerge:
+		p.summary.updated++
+	default:
+		p.summary.skipped++
+	}
+	return action
+}
+
+// resolve decides what to do with host and applies it to the config
+func (p *importPlan) resolve(host models.Host) importAction {
+	if p.seen[host.Alias] {
+		return importAction{kind: actionSkip, host: host, reason: "repeated alias in the import"}
+	}
+	p.seen[host.Alias] = true
+
+	if errs := host.Validate(); len(errs) > 0 {
+		return importAction{kind: actionSkip, host: host, reason: errs[0].Message}
+	}
+
+	i, exists := p.existing[host.Alias]
+	if !exists {
+		p.append(host)
+		return importAction{kind: actionImport, host: host}
+	}
+
+	changes := hostChanges(&p.cfg.Hosts[i], &host)
+	if len(changes) == 0 {
+		return importAction{kind: actionSkip, host: host, reason: "unchanged"}
+	}
+
+	resolution := p.conflict
+	if p.askConflict != nil {
+		resolution = p.askConflict(host.Alias, changes)
+	}
+	switch resolution {
+	case ConflictTake:
+		p.cfg.Hosts[i] = host
+		return importAction{kind: actionUpdate, host: host, changes: changes}
+	case ConflictMerge:
+		filled := mergeHost(&p.cfg.Hosts[i], &host)
+		if len(filled) == 0 {
+			return importAction{kind: actionSkip, host: host, reason: "nothing to fill in", changes: changes}
+		}
+		return importAction{kind: actionMerge, host: host, changes: changes, filled: filled}
+	case ConflictRename:
+		alias := renamedAlias(host.Alias, p.suffix, p.existing, p.seen)
+		if p.askAlias != nil {
+			alias = p.askAlias(alias)
+		}
+		host.Alias = alias
+		p.seen[alias] = true
+		p.append(host)
+		return importAction{kind: actionRename, host: host, changes: changes}
+	default:
+		return importAction{kind: actionKeep, host: host, changes: changes}
+	}
+}
+
+// append adds host to the end of the config's hosts
+func (p *importPlan) append(host models.Host) {
+	p.cfg.Hosts = append(p.cfg.Hosts, host)
+	p.existing[host.Alias] = len(p.cfg.Hosts) - 1
+}
+
+// printAction prints what happened to an imported host. Conflicts are
+// printed before the resolution, unless askConflict already showed them.
+func printAction(action importAction, opts ImportOptions) {
+	verb := func(done, planned string) string {
+		if opts.DryRun {
+			return planned
+		}
+		return done
+	}
+	host := action.host
+	if len(action.changes) > 0 && !opts.Interactive {
+		fmt.Printf("~ Conflict: %s\n", host.Alias)
+		printChanges(action.changes)
+	}
+
+	switch action.kind {
+	case actionImport:
+		fmt.Printf("+ %s: %s (%s@%s)\n", verb("Imported", "Import"), host.Alias, host.User, host.Hostname)
+	case actionRename:
+		fmt.Printf("+ %s: %s (%s@%s)\n", verb("Imported as", "Import as"), host.Alias, host.User, host.Hostname)
+	case actionUpdate:
+		fmt.Printf("✓ %s: %s (%s@%s)\n", verb("Updated", "Update"), host.Alias, host.User, host.Hostname)
+	case actionMerge:
+		fmt.Printf("✓ %s: %s (%s)\n", verb("Merged", "Merge"), host.Alias, strings.Join(action.filled, ", "))
+	case actionKeep:
+		if opts.Interactive {
+			fmt.Printf("- %s: %s\n", verb("Kept", "Keep"), host.Alias)
+		} else {
+			fmt.Printf("- %s: %s (use --overwrite, --merge, --rename-suffix or --interactive to resolve)\n", verb("Kept", "Keep"), host.Alias)
+		}
+	default:
+		fmt.Printf("- %s: %s (%s)\n", verb("Skipped", "Skip"), host.Alias, action.reason)
+	}
+}
+
+// fieldChange is a field whose value differs between two hosts
+type fieldChange struct {
+	field    string
+	from, to string
+}
+
+// hostChanges lists the fields that incoming would change on existing
+func hostChanges(existing, incoming *models.Host) []fieldChange {
+	var changes []fieldChange
+	for _, field := range models.MergeableFields() {
+		from, to := existing.FieldString(field), incoming.FieldString(field)
+		if from != to {
+			changes = append(changes, fieldChange{field: field, from: from, to: to})
+		}
+	}
+	return changes
+}
+
+// printChanges prints a field-level diff, one field per line
+func printChanges(changes []fieldChange) {
+	show := func(value string) string {
+		if value == "" {
+			return "(none)"
+		}
+		return value
+	}
+	for _, change := range changes {
+		fmt.Printf("    %-22s %s → %s\n", change.field, show(change.from), show(change.to))
+	}
+}
+
+// mergeHost fills the fields existing doesn't set from incoming and adds
+// the incoming tags, returning the names of the fields that changed. Fields
+// that go with the hostname are kept, as existing keeps its hostname.
+func mergeHost(existing, incoming *models.Host) []string {
+	var filled []string
+	for _, field := range models.MergeableFields() {
+		if field == "tags" || models.FollowsHostname(field) || existing.FieldIsSet(field) || !incoming.FieldIsSet(field) {
+			continue
+		}
+		if existing.CopyField(field, incoming) {
+			filled = append(filled, field)
+		}
+	}
+
+	added := false
+	for _, tag := range incoming.Tags {
+		if !containsTag(existing.Tags, tag) {
+			existing.Tags = append(existing.Tags, tag)
+			added = true
+		}
+	}
+	if added {
+		filled = append(filled, "tags")
+	}
+	return filled
+}
+
+// containsTag reports whether tags contains tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedAlias appends suffix to alias, numbering it if that alias is taken
+// too: web-imported, web-imported-2, ...
+func renamedAlias(alias, suffix string, existing map[string]int, seen map[string]bool) string {
+	if suffix == "" {
+		suffix = DefaultRenameSuffix
+	}
+	base := alias + suffix
+	renamed := base
+	for n := 2; ; n++ {
+		if _, taken := existing[renamed]; !taken && !seen[renamed] {
+			return renamed
+		}
+		renamed = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// askConflict asks how to resolve a conflict. Keeping the existing host is
+// the default, also when there is no more input.
+func askConflict(prompt *bufio.Reader) string {
+	for {
+		fmt.Print("  [k]eep existing, [t]ake incoming, [m]erge, [r]ename? [k] ")
+		answer, err := prompt.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "t", "take":
+			return ConflictTake
+		case "m", "merge":
+			return ConflictMerge
+		case "r", "rename":
+			return ConflictRename
+		case "k", "keep", "":
+			if err == io.EOF {
+				fmt.Println()
+			}
+			return ConflictKeep
+		}
+		if err != nil {
+			fmt.Println()
+			return ConflictKeep
+		}
+	}
+}
+
+// askAlias asks for the alias of a renamed host, suggesting one. Aliases
+// that are taken are refused.
+func askAlias(prompt *bufio.Reader, suggested string, existing map[string]int, seen map[string]bool) string {
+	for {
+		fmt.Printf("  New alias [%s]: ", suggested)
+		answer, err := prompt.ReadString('\n')
+		alias := strings.TrimSpace(answer)
+		if alias == "" {
+			if err != nil {
+				fmt.Println()
+			}
+			return suggested
+		}
+		if _, taken := existing[alias]; taken || seen[alias] {
+			fmt.Printf("  %s is taken\n", alias)
+			if err != nil {
+				return suggested
+			}
+			continue
+		}
+		return alias
+	}
+}
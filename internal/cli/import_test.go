@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"sshbuddy/internal/config"
+	"sshbuddy/pkg/models"
+)
+
+func TestImportPlan(t *testing.T) {
+	web := models.Host{Alias: "web", Hostname: "10.0.0.1", User: "admin", Port: "22", Tags: []string{"prod"}, Source: "manual"}
+
+	tests := []struct {
+		name     string
+		conflict string
+		incoming models.Host
+		want     importAction
+		wantWeb  models.Host // The existing web host afterwards
+	}{
+		{
+			name:     "new alias",
+			incoming: models.Host{Alias: "db", Hostname: "10.0.0.2"},
+			want:     importAction{kind: actionImport, host: models.Host{Alias: "db", Hostname: "10.0.0.2", User: "me", Source: "manual"}},
+			wantWeb:  web,
+		},
+		{
+			name:     "identical host",
+			incoming: models.Host{Alias: "web", Hostname: "10.0.0.1", User: "admin", Port: "22", Tags: []string{"prod"}},
+			want:     importAction{kind: actionSkip, host: web, reason: "unchanged"},
+			wantWeb:  web,
+		},
+		{
+			name:     "invalid host",
+			incoming: models.Host{Alias: "db"},
+			want:     importAction{kind: actionSkip, host: models.Host{Alias: "db", User: "me", Source: "manual"}, reason: "hostname is required"},
+			wantWeb:  web,
+		},
+		{
+			name:     "conflict kept by default",
+			incoming: models.Host{Alias: "web", Hostname: "10.0.0.9", User: "admin", Port: "22", Tags: []string{"prod"}},
+			want: importAction{
+				kind:    actionKeep,
+				host:    models.Host{Alias: "web", Hostname: "10.0.0.9", User: "admin", Port: "22", Tags: []string{"prod"}, Source: "manual"},
+				changes: []fieldChange{{field: "hostname", from: "10.0.0.1", to: "10.0.0.9"}},
+			},
+			wantWeb: web,
+		},
+		{
+			name:     "take",
+			conflict: ConflictTake,
+			incoming: models.Host{Alias: "web", Hostname: "10.0.0.9", User: "ops"},
+			want: importAction{
+				kind: actionUpdate,
+				host: models.Host{Alias: "web", Hostname: "10.0.0.9", User: "ops", Source: "manual"},
+				changes: []fieldChange{
+					{field: "hostname", from: "10.0.0.1", to: "10.0.0.9"},
+					{field: "user", from: "admin", to: "ops"},
+					{field: "port", from: "22", to: ""},
+					{field: "tags", from: "prod", to: ""},
+				},
+			},
+			wantWeb: models.Host{Alias: "web", Hostname: "10.0.0.9", User: "ops", Source: "manual"},
+		},
+		{
+			name:     "merge fills unset fields but not those of the hostname",
+			conflict: ConflictMerge,
+			incoming: models.Host{
+				Alias: "web", Hostname: "web-1", User: "ops", Port: "2222", Tags: []string{"web", "prod"},
+				ProxyJump: "bastion", PingMethod: models.PingMethodSSH, ConnectMode: models.ConnectModeDocker,
+			},
+			want: importAction{
+				kind: actionMerge,
+				host: models.Host{
+					Alias: "web", Hostname: "web-1", User: "ops", Port: "2222", Tags: []string{"web", "prod"},
+					ProxyJump: "bastion", PingMethod: models.PingMethodSSH, ConnectMode: models.ConnectModeDocker, Source: "manual",
+				},
+				changes: []fieldChange{
+					{field: "hostname", from: "10.0.0.1", to: "web-1"},
+					{field: "user", from: "admin", to: "ops"},
+					{field: "port", from: "22", to: "2222"},
+					{field: "tags", from: "prod", to: "web, prod"},
+					{field: "proxy_jump", from: "", to: "bastion"},
+					{field: "ping_method", from: "", to: models.PingMethodSSH},
+					{field: "connect_mode", from: "", to: models.ConnectModeDocker},
+				},
+				filled: []string{"proxy_jump", "tags"},
+			},
+			wantWeb: models.Host{Alias: "web", Hostname: "10.0.0.1", User: "admin", Port: "22", Tags: []string{"prod", "web"}, ProxyJump: "bastion", Source: "manual"},
+		},
+		{
+			name:     "merge with nothing to fill in",
+			conflict: ConflictMerge,
+			incoming: models.Host{Alias: "web", Hostname: "10.0.0.9", User: "admin", Port: "22", Tags: []string{"prod"}},
+			want: importAction{
+				kind:    actionSkip,
+				host:    models.Host{Alias: "web", Hostname: "10.0.0.9", User: "admin", Port: "22", Tags: []string{"prod"}, Source: "manual"},
+				reason:  "nothing to fill in",
+				changes: []fieldChange{{field: "hostname", from: "10.0.0.1", to: "10.0.0.9"}},
+			},
+			wantWeb: web,
+		},
+		{
+			name:     "rename",
+			conflict: ConflictRename,
+			incoming: models.Host{Alias: "web", Hostname: "10.0.0.9", User: "admin", Port: "22", Tags: []string{"prod"}},
+			want: importAction{
+				kind:    actionRename,
+				host:    models.Host{Alias: "web-imported", Hostname: "10.0.0.9", User: "admin", Port: "22", Tags: []string{"prod"}, Source: "manual"},
+				changes: []fieldChange{{field: "hostname", from: "10.0.0.1", to: "10.0.0.9"}},
+			},
+			wantWeb: web,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := web
+			existing.Tags = append([]string(nil), web.Tags...)
+			cfg := &models.Config{Hosts: []models.Host{existing}}
+			plan := newImportPlan(cfg, ImportOptions{Conflict: tt.conflict}, "me")
+
+			got := plan.add(tt.incoming)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(cfg.Hosts[0], tt.wantWeb) {
+				t.Errorf("web is %+v, want %+v", cfg.Hosts[0], tt.wantWeb)
+			}
+
+			added := len(cfg.Hosts) > 1
+			if wantAdded := tt.want.kind == actionImport || tt.want.kind == actionRename; added != wantAdded {
+				t.Errorf("host added: %v, want %v", added, wantAdded)
+			} else if added && !reflect.DeepEqual(cfg.Hosts[1], tt.want.host) {
+				t.Errorf("added %+v, want %+v", cfg.Hosts[1], tt.want.host)
+			}
+		})
+	}
+}
+
+func TestImportPlanRenameNumbering(t *testing.T) {
+	cfg := &models.Config{Hosts: []models.Host{
+		{Alias: "web", Hostname: "10.0.0.1", User: "admin"},
+		{Alias: "web-old", Hostname: "10.0.0.2", User: "admin"},
+	}}
+	plan := newImportPlan(cfg, ImportOptions{Conflict: ConflictRename, RenameSuffix: "-old"}, "me")
+
+	var aliases []string
+	for _, host := range []models.Host{
+		{Alias: "web-old-2", Hostname: "10.0.0.3"},   // Imported under its own alias first
+		{Alias: "web", Hostname: "10.0.0.4"},         // web-old and web-old-2 are taken
+		{Alias: "web", Hostname: "10.0.0.5"},         // Repeated alias in the import
+		{Alias: "web-old", Hostname: "10.0.0.6"},     // Conflicts with the existing web-old
+		{Alias: "web-old-old", Hostname: "10.0.0.7"}, // Taken by the previous rename, so skipped
+	} {
+		aliases = append(aliases, plan.add(host).host.Alias)
+	}
+
+	want := []string{"web-old-2", "web-old-3", "web", "web-old-old", "web-old-old"}
+	if !reflect.DeepEqual(aliases, want) {
+		t.Errorf("got aliases %q, want %q", aliases, want)
+	}
+	if want := (importSummary{imported: 3, skipped: 2}); plan.summary != want {
+		t.Errorf("got summary %+v, want %+v", plan.summary, want)
+	}
+}
+
+func TestImportPlanAsks(t *testing.T) {
+	cfg := &models.Config{Hosts: []models.Host{{Alias: "web", Hostname: "10.0.0.1", User: "admin"}}}
+	plan := newImportPlan(cfg, ImportOptions{Conflict: ConflictTake}, "me")
+	var asked []fieldChange
+	plan.askConflict = func(alias string, changes []fieldChange) string {
+		asked = changes
+		return ConflictRename
+	}
+	plan.askAlias = func(suggested string) string {
+		if suggested != "web-imported" {
+			t.Errorf("suggested %s, want web-imported", suggested)
+		}
+		return "web-2"
+	}
+
+	action := plan.add(models.Host{Alias: "web", Hostname: "10.0.0.9"})
+	if action.kind != actionRename || action.host.Alias != "web-2" {
+		t.Errorf("got %s %s, want rename to web-2", action.kind, action.host.Alias)
+	}
+	if len(asked) != 2 {
+		t.Errorf("asked about changes %+v, want hostname and user", asked)
+	}
+	if cfg.Hosts[0].Hostname != "10.0.0.1" {
+		t.Error("the existing host was taken over despite the answer")
+	}
+}
+
+func TestImportHostsDryRunDoesNotSave(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := &models.Config{}
+	importHosts(cfg, []models.Host{{Alias: "web", Hostname: "10.0.0.1", User: "admin"}}, ImportOptions{DryRun: true})
+
+	path, err := config.GetDataPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote %s", path)
+	}
+
+	importHosts(cfg, []models.Host{{Alias: "db", Hostname: "10.0.0.2", User: "admin"}}, ImportOptions{})
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("import didn't save: %v", err)
+	}
+}
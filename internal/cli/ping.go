@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sshbuddy/internal/config"
+	"sshbuddy/internal/ssh"
+	"sshbuddy/pkg/models"
+	"strconv"
+	"strings"
+)
+
+// pingStatus is the machine-readable result of probing one host
+type pingStatus struct {
+	Alias     string  `json:"alias"`
+	Hostname  string  `json:"hostname"`
+	Port      string  `json:"port"`
+	Status    string  `json:"status"` // "up", "down" or "bastion-down"
+	Up        bool    `json:"up"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Method    string  `json:"method"`
+	Via       string  `json:"via,omitempty"`
+	Banner    string  `json:"banner,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// PingHosts probes all hosts, or those whose alias matches pattern (a
+// case-insensitive glob) and that carry tag, then prints the results as a
+// table, JSON or CSV. It exits with status 1 if any selected host is down.
+func PingHosts(pattern, tag, format string) {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	var hosts []models.Host
+	for _, host := range cfg.Hosts {
+		if matchesHostFilter(host, pattern, tag) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		fmt.Fprintln(os.Stderr, "No hosts match the given pattern or tag")
+		os.Exit(1)
+	}
+
+	// Probe concurrently, then restore the configured host order
+	results := make(chan ssh.PingResult)
+	go ssh.PingAll(context.Background(), hosts, ssh.NewPingOptions(cfg), results)
+
+	byAlias := make(map[string]ssh.PingResult, len(hosts))
+	history := config.LoadPingHistory()
+	for result := range results {
+		byAlias[result.Host.Alias] = result
+		if !result.BastionDown() {
+			history.Record(result.Host.Alias, result.Status, result.Latency)
+		}
+	}
+	config.SavePingHistory(history)
+
+	statuses := make([]pingStatus, 0, len(hosts))
+	anyDown := false
+	for _, host := range hosts {
+		status := newPingStatus(byAlias[host.Alias])
+		anyDown = anyDown || !status.Up
+		statuses = append(statuses, status)
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding results: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	case "csv":
+		writePingCSV(statuses)
+	default:
+		printPingTable(statuses)
+	}
+
+	if anyDown {
+		os.Exit(1)
+	}
+}
+
+// matchesHostFilter reports whether host is selected by pattern and tag;
+// empty filters match everything
+func matchesHostFilter(host models.Host, pattern, tag string) bool {
+	if pattern != "" {
+		matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(host.Alias))
+		if err != nil || !matched {
+			return false
+		}
+	}
+	if tag != "" {
+		for _, t := range host.Tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// newPingStatus converts a probe result for output
+func newPingStatus(result ssh.PingResult) pingStatus {
+	port := result.Host.Port
+	if port == "" && !result.Host.IsDocker() {
+		port = "22"
+	}
+
+	status := pingStatus{
+		Alias:    result.Host.Alias,
+		Hostname: result.Host.Hostname,
+		Port:     port,
+		Status:   "down",
+		Up:       result.Status,
+		Method:   result.Method,
+		Via:      result.Via,
+		Banner:   result.Banner,
+	}
+	switch {
+	case result.Status:
+		status.Status = "up"
+		status.LatencyMs = float64(result.Latency.Microseconds()) / 1000
+	case result.BastionDown():
+		status.Status = "bastion-down"
+	}
+	if result.Err != nil {
+		status.Error = result.Err.Error()
+	}
+	return status
+}
+
+// printPingTable prints results as an aligned table with a summary line
+func printPingTable(statuses []pingStatus) {
+	up := 0
+	fmt.Printf("  %-20s %-30s %-13s %10s  %s\n", "ALIAS", "HOST", "STATUS", "LATENCY", "DETAILS")
+	for _, s := range statuses {
+		latency := "-"
+		if s.Up {
+			up++
+			latency = fmt.Sprintf("%.1fms", s.LatencyMs)
+		}
+
+		details := s.Error
+		if s.Up {
+			details = s.Banner
+		}
+		if s.Via != "" {
+			details = strings.TrimSpace("via " + s.Via + " " + details)
+		}
+
+		target := s.Hostname + ":" + s.Port
+		if s.Method == models.ConnectModeDocker {
+			target = "docker:" + s.Hostname
+		}
+		line := fmt.Sprintf("  %-20s %-30s %-13s %10s  %s", s.Alias, target, s.Status, latency, details)
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	fmt.Printf("\n%d/%d hosts up\n", up, len(statuses))
+}
+
+// writePingCSV writes results as CSV with a header row
+func writePingCSV(statuses []pingStatus) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"alias", "hostname", "port", "status", "up", "latency_ms", "method", "via", "banner", "error"})
+	for _, s := range statuses {
+		latency := ""
+		if s.Up {
+			latency = strconv.FormatFloat(s.LatencyMs, 'f', 3, 64)
+		}
+		w.Write([]string{
+			s.Alias, s.Hostname, s.Port, s.Status, strconv.FormatBool(s.Up),
+			latency, s.Method, s.Via, s.Banner, s.Error,
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sshbuddy/internal/ansible"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		var sources []Source
+		for i, ansibleCfg := range cfg.Ansible {
+			sources = append(sources, ansibleSource{cfg: ansibleCfg, index: i})
+		}
+		return sources
+	})
+}
+
+// ansibleSource provides the hosts of an Ansible inventory file, tagged with
+// their groups
+type ansibleSource struct {
+	cfg   models.AnsibleSourceConfig
+	index int // Position in Config.Ansible
+}
+
+func (s ansibleSource) Name() string  { return s.cfg.SourceName() }
+func (s ansibleSource) Priority() int { return priorityAnsible + s.index }
+func (s ansibleSource) Enabled() bool { return s.cfg.Enabled && strings.TrimSpace(s.cfg.Path) != "" }
+
+// Load parses the inventory file. A leading ~/ in its path is expanded.
+func (s ansibleSource) Load(ctx context.Context) ([]models.Host, error) {
+	inv, err := ansible.ParseFile(expandHomePath(strings.TrimSpace(s.cfg.Path)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name(), err)
+	}
+	return inv.Hosts(), nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sshbuddy/internal/ssh"
+	"sshbuddy/internal/termix"
+	"sshbuddy/pkg/models"
+)
+
+// Priorities of the built-in sources: manual hosts override SSH config files,
+// which override Termix, then HTTP host lists, inventory commands, Ansible
+// inventories, Terraform states, Vagrant machines and Docker containers.
+// Hosts discovered in known_hosts come last.
+const (
+	priorityManual     = 0
+	prioritySSHConfig  = 100 // Plus the index of the config file
+	priorityTermix     = 200
+	priorityHTTP       = 250 // Plus the index of the HTTP source
+	priorityExec       = 300 // Plus the index of the exec source
+	priorityAnsible    = 350 // Plus the index of the Ansible source
+	priorityTerraform  = 400 // Plus the index of the Terraform source
+	priorityVagrant    = 450
+	priorityDocker     = 460
+	priorityKnownHosts = 500
+)
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		return []Source{manualSource{cfg}}
+	})
+	RegisterSource(func(cfg *models.Config) []Source {
+		var sources []Source
+		for i, path := range cfg.SSH.Paths() {
+			sources = append(sources, sshConfigSource{cfg: cfg, index: i, path: path})
+		}
+		return sources
+	})
+	RegisterSource(func(cfg *models.Config) []Source {
+		return []Source{termixSource{cfg}}
+	})
+}
+
+// manualSource provides the hosts stored in sshbuddy's own config file
+type manualSource struct {
+	cfg *models.Config
+}
+
+func (s manualSource) Name() string  { return "manual" }
+func (s manualSource) Priority() int { return priorityManual }
+func (s manualSource) Enabled() bool { return s.cfg.Sources.SSHBuddyEnabled }
+
+func (s manualSource) Load(ctx context.Context) ([]models.Host, error) {
+	return s.cfg.Hosts, nil
+}
+
+// sshConfigSource provides the hosts of one SSH config file
+type sshConfigSource struct {
+	cfg   *models.Config
+	index int // Position in cfg.SSH.Paths()
+	path  string
+}
+
+func (s sshConfigSource) Name() string  { return s.cfg.SSH.SourceName(s.index) }
+func (s sshConfigSource) Priority() int { return prioritySSHConfig + s.index }
+func (s sshConfigSource) Enabled() bool { return s.cfg.Sources.SSHConfigEnabled && s.cfg.SSH.Enabled }
+
+func (s sshConfigSource) Load(ctx context.Context) ([]models.Host, error) {
+	var hosts []models.Host
+	var err error
+	if s.cfg.SSH.ResolveWithSSH {
+		// Let OpenSSH compute the effective settings (cached per config mtime)
+		cachePath, _ := getCachePath("ssh-resolve-cache.json")
+		hosts, err = ssh.LoadHostsFromSSHConfigResolved(ctx, s.path, cachePath)
+	} else {
+		hosts, err = ssh.LoadHostsFromSSHConfig(s.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return hosts, nil
+}
+
+// termixSource provides the hosts of a Termix server
+type termixSource struct {
+	cfg *models.Config
+}
+
+func (s termixSource) Name() string     { return "termix" }
+func (s termixSource) Priority() int    { return priorityTermix }
+func (s termixSource) CacheKey() string { return s.cfg.Termix.BaseURL }
+
+func (s termixSource) Enabled() bool {
+	return s.cfg.Sources.TermixEnabled && s.cfg.Termix.Enabled && s.cfg.Termix.BaseURL != ""
+}
+
+// Load fetches the hosts with the cached JWT. A missing or expired token
+// yields a *termix.AuthError so the caller can prompt for credentials.
+func (s termixSource) Load(ctx context.Context) ([]models.Host, error) {
+	logError("Termix config loaded", fmt.Errorf("baseUrl=%s", s.cfg.Termix.BaseURL))
+
+	client := termix.NewClient(s.cfg.Termix.BaseURL, s.cfg.Termix.JWT, s.cfg.Termix.JWTExpiry)
+	hosts, err := client.FetchHosts("", "")
+	if err != nil {
+		return nil, err
+	}
+	logError("Termix hosts fetched successfully", fmt.Errorf("count=%d", len(hosts)))
+
+	// Save JWT if updated
+	if client.GetJWT() != s.cfg.Termix.JWT || client.GetJWTExpiry() != s.cfg.Termix.JWTExpiry {
+		s.cfg.Termix.JWT = client.GetJWT()
+		s.cfg.Termix.JWTExpiry = client.GetJWTExpiry()
+		if err := saveTermixToken(client.GetJWT(), client.GetJWTExpiry()); err != nil {
+			logError("Saving the Termix token", err)
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// dockerComposeProjectLabel names the Compose project a container belongs to
+const dockerComposeProjectLabel = "com.docker.compose.project"
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		return []Source{dockerSource{cfg.Docker}}
+	})
+}
+
+// dockerSource provides the running Docker containers. Its hosts connect
+// with docker exec rather than ssh.
+type dockerSource struct {
+	cfg models.DockerSourceConfig
+}
+
+func (s dockerSource) Name() string  { return models.DockerSource }
+func (s dockerSource) Priority() int { return priorityDocker }
+func (s dockerSource) Enabled() bool { return s.cfg.Enabled }
+
+// dockerContainer is a line of `docker ps --format '{{json .}}'`
+type dockerContainer struct {
+	ID     string `json:"ID"`
+	Names  string `json:"Names"`  // Comma-separated
+	Labels string `json:"Labels"` // Comma-separated key=value pairs
+}
+
+// Load lists the running containers with docker ps. Each container is named
+// after its first name, and tagged "docker" and with its Compose project.
+func (s dockerSource) Load(ctx context.Context) ([]models.Host, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "docker", "ps", "--no-trunc", "--format", "{{json .}}")
+	cmd.WaitDelay = execWaitDelay
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s: docker ps timed out after %s", s.Name(), s.cfg.Timeout())
+		}
+		return nil, fmt.Errorf("%s: %w: %s", s.Name(), err, strings.TrimSpace(stderr.String()))
+	}
+
+	hosts := []models.Host{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // Labels can be long
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var container dockerContainer
+		if err := json.Unmarshal([]byte(line), &container); err != nil {
+			return nil, fmt.Errorf("%s: unexpected docker ps output: %w", s.Name(), err)
+		}
+
+		name, _, _ := strings.Cut(container.Names, ",")
+		if name == "" {
+			name = container.ID
+		}
+		tags := []string{"docker"}
+		if project := dockerLabel(container.Labels, dockerComposeProjectLabel); project != "" {
+			tags = append(tags, project)
+		}
+		hosts = append(hosts, models.Host{
+			Alias:       name,
+			Hostname:    name,
+			User:        s.cfg.User,
+			Tags:        tags,
+			ConnectMode: models.ConnectModeDocker,
+		})
+	}
+	return hosts, scanner.Err()
+}
+
+// dockerLabel returns the value of a label in the Labels column of docker ps
+func dockerLabel(labels, key string) string {
+	for _, label := range strings.Split(labels, ",") {
+		if k, v, ok := strings.Cut(label, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
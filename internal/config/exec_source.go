@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sshbuddy/pkg/models"
+	"strings"
+	"time"
+)
+
+// execWaitDelay is how long to wait for the output to close once an exec
+// source's command has been killed
+const execWaitDelay = 500 * time.Millisecond
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		var sources []Source
+		for i, execCfg := range cfg.Exec {
+			sources = append(sources, execSource{cfg: execCfg, index: i})
+		}
+		return sources
+	})
+}
+
+// execSource provides the hosts printed by an inventory command
+type execSource struct {
+	cfg   models.ExecSourceConfig
+	index int // Position in Config.Exec
+}
+
+func (s execSource) Name() string     { return s.cfg.SourceName() }
+func (s execSource) Priority() int    { return priorityExec + s.index }
+func (s execSource) Enabled() bool    { return s.cfg.Enabled && strings.TrimSpace(s.cfg.Command) != "" }
+func (s execSource) CacheKey() string { return s.cfg.Command }
+
+// Load runs the command with sh -c and parses its output as a JSON array of
+// hosts. Hosts that fail validation are logged and left out.
+func (s execSource) Load(ctx context.Context) ([]models.Host, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.cfg.Command)
+	// Don't wait for children of the shell that still hold the output open
+	// after the timeout killed it
+	cmd.WaitDelay = execWaitDelay
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s: command timed out after %s", s.Name(), s.cfg.Timeout())
+		}
+		return nil, fmt.Errorf("%s: %w: %s", s.Name(), err, strings.TrimSpace(stderr.String()))
+	}
+
+	var hosts []models.Host
+	if err := json.Unmarshal(output, &hosts); err != nil {
+		return nil, fmt.Errorf("%s: output is not a JSON array of hosts: %w", s.Name(), err)
+	}
+
+	valid := hosts[:0]
+	for i, host := range hosts {
+		if errs := host.Validate(); len(errs) > 0 {
+			for _, e := range errs {
+				e.Index = i
+				logError(fmt.Sprintf("Source %s: skipping host %q", s.Name(), host.Alias), e)
+			}
+			continue
+		}
+		valid = append(valid, host)
+	}
+	return valid, nil
+}
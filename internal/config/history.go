@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sshbuddy/internal/ssh"
+)
+
+// pingHistoryFile stores recent probe results so they survive restarts and
+// can be inspected from the CLI
+const pingHistoryFile = "ping-history.json"
+
+// LoadPingHistory reads the stored ping history. A missing or unreadable
+// file yields an empty history.
+func LoadPingHistory() ssh.PingHistory {
+	history := make(ssh.PingHistory)
+
+	path, err := getCachePath(pingHistoryFile)
+	if err != nil {
+		return history
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return history
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		logError("Failed to parse ping history", err)
+		return make(ssh.PingHistory)
+	}
+	return history
+}
+
+// SavePingHistory writes the ping history
+func SavePingHistory(history ssh.PingHistory) error {
+	path, err := getCachePath(pingHistoryFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sshbuddy/internal/inventory"
+	"sshbuddy/pkg/models"
+	"strings"
+	"sync"
+)
+
+// httpSourceCacheFile stores the last response of each HTTP source, so the
+// next request can be conditional on its ETag and Last-Modified date
+const httpSourceCacheFile = "http-sources-cache.json"
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		var sources []Source
+		for i, httpCfg := range cfg.HTTP {
+			sources = append(sources, httpSource{cfg: httpCfg, index: i})
+		}
+		return sources
+	})
+}
+
+// httpSource provides the hosts of a JSON host list published over HTTP
+type httpSource struct {
+	cfg   models.HTTPSourceConfig
+	index int // Position in Config.HTTP
+}
+
+func (s httpSource) Name() string     { return s.cfg.SourceName() }
+func (s httpSource) Priority() int    { return priorityHTTP + s.index }
+func (s httpSource) Enabled() bool    { return s.cfg.Enabled && strings.TrimSpace(s.cfg.URL) != "" }
+func (s httpSource) CacheKey() string { return s.cfg.CacheKey() }
+
+// Load fetches the host list, reusing the previous response when the server
+// reports it as unchanged
+func (s httpSource) Load(ctx context.Context) ([]models.Host, error) {
+	previous := loadHTTPSnapshot(s.Name(), s.CacheKey())
+
+	snapshot, err := inventory.NewClient(s.cfg).FetchHosts(ctx, previous)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Skipped > 0 {
+		logError(fmt.Sprintf("Source %s", s.Name()), fmt.Errorf("skipped %d entries without alias or hostname", snapshot.Skipped))
+	}
+	if !snapshot.NotModified {
+		if err := saveHTTPSnapshot(s.Name(), s.CacheKey(), snapshot); err != nil {
+			logError("Failed to save HTTP source cache", err)
+		}
+	}
+	return snapshot.Hosts, nil
+}
+
+// httpCacheEntry is the cached response of one HTTP source
+type httpCacheEntry struct {
+	Key      string             `json:"key"`
+	Snapshot inventory.Snapshot `json:"snapshot"`
+}
+
+// httpCacheMu serializes updates of the cache file by sources loading
+// concurrently
+var httpCacheMu sync.Mutex
+
+// loadHTTPSnapshot returns the cached response of a source, or nil if there
+// is none for the same cache key
+func loadHTTPSnapshot(name, key string) *inventory.Snapshot {
+	httpCacheMu.Lock()
+	defer httpCacheMu.Unlock()
+
+	entry, ok := loadHTTPCache()[name]
+	if !ok || entry.Key != key {
+		return nil
+	}
+	return &entry.Snapshot
+}
+
+// saveHTTPSnapshot stores the response a source just received
+func saveHTTPSnapshot(name, key string, snapshot *inventory.Snapshot) error {
+	httpCacheMu.Lock()
+	defer httpCacheMu.Unlock()
+
+	cache := loadHTTPCache()
+	cache[name] = httpCacheEntry{Key: key, Snapshot: *snapshot}
+
+	path, err := getCachePath(httpSourceCacheFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// loadHTTPCache reads the HTTP source cache, returning an empty cache on any
+// error
+func loadHTTPCache() map[string]httpCacheEntry {
+	cache := make(map[string]httpCacheEntry)
+
+	path, err := getCachePath(httpSourceCacheFile)
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logError("Failed to parse HTTP source cache", err)
+		return make(map[string]httpCacheEntry)
+	}
+	return cache
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sshbuddy/internal/ssh"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		return []Source{knownHostsSource{cfg: cfg.KnownHosts, configured: cfg.Hosts}}
+	})
+}
+
+// knownHostsSource discovers the hosts SSH has connected to from its
+// known_hosts file
+type knownHostsSource struct {
+	cfg        models.KnownHostsConfig
+	configured []models.Host // The hosts of config.json
+}
+
+func (s knownHostsSource) Name() string  { return models.KnownHostsSource }
+func (s knownHostsSource) Priority() int { return priorityKnownHosts }
+func (s knownHostsSource) Enabled() bool { return s.cfg.Enabled }
+
+// WatchPaths returns the known_hosts file, so hosts show up after their
+// first connection
+func (s knownHostsSource) WatchPaths() []string {
+	return []string{expandHomePath(s.path())}
+}
+
+// path returns the configured known_hosts file
+func (s knownHostsSource) path() string {
+	if path := strings.TrimSpace(s.cfg.Path); path != "" {
+		return path
+	}
+	return ssh.DefaultKnownHostsPath
+}
+
+// Load lists the hosts of known_hosts that aren't in config.json. Hashed
+// entries are matched against the hosts of config.json, so that they are
+// recognized as configured, and with ScanHistory against the targets of ssh
+// commands in the shell history, which also provide the user the host was
+// connected as.
+func (s knownHostsSource) Load(ctx context.Context) ([]models.Host, error) {
+	var candidates []ssh.KnownHost
+	configured := make(map[string]bool)
+	for _, host := range s.configured {
+		if host.IsDocker() {
+			continue
+		}
+		candidate := ssh.KnownHost{Hostname: host.Hostname, Port: host.Port}
+		if candidate.Port == "22" {
+			candidate.Port = ""
+		}
+		candidates = append(candidates, candidate)
+		configured[candidate.Hostname+":"+candidate.Port] = true
+	}
+
+	var history []ssh.KnownHost
+	if s.cfg.ScanHistory {
+		history = ssh.ShellHistoryHosts()
+		candidates = append(candidates, history...)
+	}
+
+	known, err := ssh.ParseKnownHosts(s.path(), candidates)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name(), err)
+	}
+
+	users := make(map[string]string)
+	for _, candidate := range history {
+		if candidate.User != "" {
+			users[candidate.Hostname+":"+candidate.Port] = candidate.User
+		}
+	}
+
+	hosts := make([]models.Host, 0, len(known))
+	for _, k := range known {
+		// A line is one machine: any configured name means it is known
+		isConfigured := configured[k.Hostname+":"+k.Port]
+		for _, other := range k.Others {
+			isConfigured = isConfigured || configured[other.Hostname+":"+other.Port]
+		}
+		if isConfigured {
+			continue
+		}
+
+		alias := k.Hostname
+		if k.Port != "" {
+			alias += "-" + k.Port
+		}
+		user := k.User
+		if user == "" {
+			user = users[k.Hostname+":"+k.Port]
+		}
+		hosts = append(hosts, models.Host{
+			Alias:    alias,
+			Hostname: k.Hostname,
+			Port:     k.Port,
+			User:     user,
+		})
+	}
+	return hosts, nil
+}
+
+// dropKnownDiscoveries leaves out the discovered hosts that another source
+// already defines, by alias or by hostname and port, so only hosts missing
+// from every config are offered
+func dropKnownDiscoveries(results []SourceResult) []SourceResult {
+	known := make(map[string]bool)
+	for _, result := range results {
+		if result.Name == models.KnownHostsSource {
+			continue
+		}
+		for _, host := range result.Hosts {
+			port := host.Port
+			if port == "22" {
+				port = ""
+			}
+			known[host.Alias] = true
+			known[host.Hostname+":"+port] = true
+		}
+	}
+
+	filtered := make([]SourceResult, len(results))
+	for i, result := range results {
+		filtered[i] = result
+		if result.Name != models.KnownHostsSource {
+			continue
+		}
+		var hosts []models.Host
+		for _, host := range result.Hosts {
+			if !known[host.Alias] && !known[host.Hostname+":"+host.Port] {
+				hosts = append(hosts, host)
+			}
+		}
+		filtered[i].Hosts = hosts
+	}
+	return filtered
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+// hashKnownHost hashes pattern like HashKnownHosts does
+func hashKnownHost(salt, pattern string) string {
+	mac := hmac.New(sha1.New, []byte(salt))
+	mac.Write([]byte(pattern))
+	return "|1|" + base64.StdEncoding.EncodeToString([]byte(salt)) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestKnownHostsSource(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	const key = " ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"
+	knownHosts := "" +
+		"web.example.com,203.0.113.5" + key + // Configured by its address
+		hashKnownHost("salt-one-1234567890", "db.internal") + key + // Configured, hashed
+		hashKnownHost("salt-two-1234567890", "build.example.com") + key + // Only in the history
+		"new.example.com,203.0.113.8" + key
+	path := filepath.Join(home, "known_hosts")
+	if err := os.WriteFile(path, []byte(knownHosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+	history := "ls\nssh ci@build.example.com\n"
+	if err := os.WriteFile(filepath.Join(home, ".bash_history"), []byte(history), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	configured := []models.Host{
+		{Alias: "web", Hostname: "203.0.113.5", User: "admin"},
+		{Alias: "db", Hostname: "db.internal", User: "admin", Port: "22"},
+	}
+
+	tests := []struct {
+		name        string
+		scanHistory bool
+		want        []models.Host
+	}{
+		{"without history", false, []models.Host{
+			{Alias: "new.example.com", Hostname: "new.example.com"},
+		}},
+		{"with history", true, []models.Host{
+			{Alias: "build.example.com", Hostname: "build.example.com", User: "ci"},
+			{Alias: "new.example.com", Hostname: "new.example.com"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := knownHostsSource{
+				cfg:        models.KnownHostsConfig{Enabled: true, Path: path, ScanHistory: tt.scanHistory},
+				configured: configured,
+			}
+			got, err := source.Load(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
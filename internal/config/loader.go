@@ -1,24 +1,50 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
-	"sshbuddy/internal/ssh"
 	"sshbuddy/internal/termix"
 	"sshbuddy/pkg/models"
 )
 
 // LoadConfig loads configuration and aggregates hosts from all enabled sources
-// Priority order: Manual (highest) → SSH Config → Termix (lowest)
+// Default priority order: Manual (highest) → SSH Config → Termix (lowest), which
+// the merge section of the config can reorder and refine per field
 // This allows local overrides (Manual) to take precedence over external sources while tracking availability
 func LoadConfig() (*models.Config, error) {
+	return LoadConfigContext(context.Background())
+}
+
+// LoadConfigContext is like LoadConfig; ctx is passed to every source
+func LoadConfigContext(ctx context.Context) (*models.Config, error) {
+	config, authErr, err := loadConfig(ctx, false)
+	if err == nil && authErr != nil {
+		return nil, authErr // Logic for auth flow
+	}
+	return config, err
+}
+
+// LoadConfigNoLogin is like LoadConfigContext for callers that can't ask for
+// credentials. Termix needing a login is handled like Termix being
+// unreachable: its cached hosts are used, and the *termix.AuthError is
+// returned as authErr so the caller can warn about it.
+func LoadConfigNoLogin(ctx context.Context) (config *models.Config, authErr error, err error) {
+	return loadConfig(ctx, true)
+}
+
+// loadConfig loads the config and every enabled source. Unless continueOnAuth
+// is set, it stops at the first source that needs credentials.
+func loadConfig(ctx context.Context, continueOnAuth bool) (*models.Config, error, error) {
 	// Load base config from file (Manual hosts)
 	config, err := LoadConfigRaw()
 	if err != nil {
 		logError("LoadConfigRaw failed", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Initialize favorites map if nil
@@ -26,145 +52,94 @@ func LoadConfig() (*models.Config, error) {
 		config.Favorites = make(map[string]bool)
 	}
 
-	// Map to track hosts by alias: alias -> host
-	hostMap := make(map[string]*models.Host)
-
-	// Track all hosts in order of processing to maintain sort stability where possible
-	// We will process sources in priority order: Manual -> SSH Config -> Termix
-
-	// PRIORITY 1: Manual hosts (HIGHEST PRIORITY - Overrides everything)
-	if config.Sources.SSHBuddyEnabled {
-		for i := range config.Hosts {
-			// Ensure source is set
-			if config.Hosts[i].Source == "" {
-				config.Hosts[i].Source = "manual"
-			}
-			host := config.Hosts[i]
-			host.AvailableIn = []string{"manual"}
-
-			// Initialize variants
-			host.Variants = make(map[string]*models.Host)
-			selfCopy := host
-			host.Variants["manual"] = &selfCopy
-
-			hostMap[host.Alias] = &host
+	// Load every enabled source in priority order. A failing source is
+	// skipped, and served from the offline cache if it has one.
+	var results []SourceResult
+	var authErr error
+	for _, source := range Sources(config) {
+		if !source.Enabled() {
+			continue
 		}
-	} else {
-		// Clear hosts if manual source disabled
-		config.Hosts = []models.Host{}
-	}
 
-	// PRIORITY 2: SSH Config
-	if config.Sources.SSHConfigEnabled && config.SSH.Enabled {
-		sshHosts, err := ssh.LoadHostsFromSSHConfig()
-		if err == nil {
-			// Process all hosts: merge duplicates and track sources
-			for _, host := range sshHosts { // Changed from allHosts to sshHosts
-				// Ensure source is set for SSH hosts
-				host.Source = "ssh-config"
-
-				if existing, found := hostMap[host.Alias]; found {
-					// Host already exists
-					existing.AvailableIn = append(existing.AvailableIn, host.Source)
-
-					// Add this variant
-					if existing.Variants == nil {
-						existing.Variants = make(map[string]*models.Host)
-						// Add the existing/winner host as variant for its source
-						winnerCopy := *existing
-						existing.Variants[existing.Source] = &winnerCopy
-					}
-					// Add the new shadowed host as variant
-					shadowedCopy := host
-					existing.Variants[host.Source] = &shadowedCopy
-				} else {
-					// New host - initialize AvailableIn with its source
-					host.AvailableIn = []string{host.Source}
-					// Initialize variants
-					host.Variants = make(map[string]*models.Host)
-					// Store self as variant
-					selfCopy := host
-					host.Variants[host.Source] = &selfCopy
-
-					hostMap[host.Alias] = &host
-				}
+		result := LoadSource(ctx, source)
+		var termixAuthErr *termix.AuthError
+		if errors.As(result.Err, &termixAuthErr) {
+			authErr = result.Err
+			if !continueOnAuth {
+				return nil, authErr, nil
 			}
 		}
+		results = append(results, result)
 	}
 
-	// PRIORITY 3: Termix API (LOWEST PRIORITY)
-	if config.Sources.TermixEnabled && config.Termix.Enabled && config.Termix.BaseURL != "" {
-		logError("Termix config loaded", fmt.Errorf("baseUrl=%s", config.Termix.BaseURL))
+	ApplySourceResults(config, results)
+
+	return config, authErr, nil
+}
 
-		client := termix.NewClient(config.Termix.BaseURL, config.Termix.JWT, config.Termix.JWTExpiry)
+// SourceResult is the outcome of loading one source
+type SourceResult struct {
+	Name  string
+	Hosts []models.Host
+	Err   error // Why the source failed to load, if it did
+	// StaleSince is the time of the cached result when a failed offline
+	// source is served from the cache, and zero otherwise
+	StaleSince time.Time
+}
 
-		// Try to fetch hosts without credentials first
-		termixHosts, termixFetchErr := client.FetchHosts("", "")
+// LoadSource loads the hosts of one source. Offline sources fall back to
+// their last successful result, which is updated whenever they load.
+func LoadSource(ctx context.Context, source Source) SourceResult {
+	result := SourceResult{Name: source.Name()}
+	hosts, err := source.Load(ctx)
 
-		// Handle auth errors (same as before)
-		if termixFetchErr != nil {
-			if _, isAuthError := termixFetchErr.(*termix.AuthError); isAuthError {
-				return nil, termixFetchErr // Logic for auth flow
-			}
-			// Log but don't fail everything if Termix fails
-			logError("Termix FetchHosts failed", termixFetchErr)
-			// Proceed without Termix hosts (or maybe show error in UI?)
-			// For now, we'll just log it and continue
-		} else {
-			logError("Termix hosts fetched successfully", fmt.Errorf("count=%d", len(termixHosts)))
-
-			for _, termixHost := range termixHosts {
-				if existing, found := hostMap[termixHost.Alias]; found {
-					// Host exists - just add source availability
-					existing.AvailableIn = append(existing.AvailableIn, "termix")
-
-					// Add Termix variant
-					if existing.Variants == nil {
-						existing.Variants = make(map[string]*models.Host)
-					}
-					// Ensure existing/winner is stored as variant
-					if existing.Variants[existing.Source] == nil {
-						winnerCopy := *existing
-						existing.Variants[existing.Source] = &winnerCopy
-					}
-					// Add shadowed variant
-					shadowedCopy := termixHost
-					existing.Variants["termix"] = &shadowedCopy
-				} else {
-					// New host
-					termixHost.AvailableIn = []string{"termix"}
-					// Initialize variants
-					termixHost.Variants = make(map[string]*models.Host)
-					selfCopy := termixHost
-					termixHost.Variants["termix"] = &selfCopy
-
-					hostMap[termixHost.Alias] = &termixHost
-				}
+	offline, isOffline := source.(OfflineSource)
+	if err != nil {
+		result.Err = err
+		logError(fmt.Sprintf("Source %s failed", source.Name()), err)
+		if isOffline {
+			if entry, ok := cachedSourceHosts(source.Name(), offline.CacheKey()); ok {
+				result.Hosts = entry.Hosts
+				result.StaleSince = entry.SavedAt
 			}
+		}
+		return result
+	}
 
-			// Save JWT if updated
-			if client.GetJWT() != config.Termix.JWT || client.GetJWTExpiry() != config.Termix.JWTExpiry {
-				config.Termix.JWT = client.GetJWT()
-				config.Termix.JWTExpiry = client.GetJWTExpiry()
-				SaveConfig(config)
-			}
+	result.Hosts = hosts
+	if isOffline {
+		if err := cacheSourceHosts(source.Name(), offline.CacheKey(), hosts); err != nil {
+			logError("Failed to save source cache", err)
 		}
 	}
+	return result
+}
 
-	// Convert map back to slice
-	config.Hosts = make([]models.Host, 0, len(hostMap))
-	for _, host := range hostMap {
-		// Apply favorite status from saved config
-		if config.Favorites[host.Alias] {
-			host.Favorite = true
+// ApplySourceResults replaces the hosts of config with the merged hosts of
+// results, given in precedence order, and applies the saved favorites
+func ApplySourceResults(config *models.Config, results []SourceResult) {
+	config.StaleSources = nil
+	for _, result := range results {
+		if result.StaleSince.IsZero() {
+			continue
+		}
+		if config.StaleSources == nil {
+			config.StaleSources = make(map[string]time.Time)
+		}
+		config.StaleSources[result.Name] = result.StaleSince
+	}
+
+	config.Hosts = mergeHosts(dropKnownDiscoveries(results), config.Merge)
+
+	// Apply favorite status from saved config
+	for i := range config.Hosts {
+		if config.Favorites[config.Hosts[i].Alias] {
+			config.Hosts[i].Favorite = true
 		}
-		config.Hosts = append(config.Hosts, *host)
 	}
 
 	// Sort hosts: favorites first, then alphabetically
 	sortHostsByFavorite(config.Hosts)
-
-	return config, nil
 }
 
 // sortHostsByFavorite sorts hosts with favorites at the top, then alphabetically by alias
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sshbuddy/internal/termix"
+	"sshbuddy/pkg/models"
+)
+
+func TestLoadConfigTermixAuthError(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	useSources(t,
+		fakeSource{name: "manual", priority: 0, hosts: []models.Host{{Alias: "web", Hostname: "10.0.0.1"}}},
+		fakeSource{name: "termix", priority: 200, err: &termix.AuthError{Message: "token expired"}},
+	)
+
+	var authErr *termix.AuthError
+	if _, err := LoadConfig(); !errors.As(err, &authErr) {
+		t.Errorf("LoadConfig: got error %v, want an AuthError", err)
+	}
+
+	cfg, err, loadErr := LoadConfigNoLogin(context.Background())
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	if !errors.As(err, &authErr) {
+		t.Errorf("LoadConfigNoLogin: got auth error %v, want an AuthError", err)
+	}
+	if len(cfg.Hosts) != 1 || cfg.Hosts[0].Alias != "web" {
+		t.Errorf("got hosts %+v, want the other sources' hosts", cfg.Hosts)
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sshbuddy/pkg/models"
+	"time"
+)
+
+// Source provides hosts for the aggregated host list. When several sources
+// define the same alias, the one with the lowest priority value wins and the
+// others are kept as variants of the host.
+type Source interface {
+	// Name is recorded in Host.Source and Host.AvailableIn
+	Name() string
+	// Priority orders sources; lower values take precedence
+	Priority() int
+	// Enabled reports whether the source should be loaded
+	Enabled() bool
+	// Load returns the source's hosts
+	Load(ctx context.Context) ([]models.Host, error)
+}
+
+// WatchedSource is a source read from local files. The TUI reloads it when
+// one of the files changes.
+type WatchedSource interface {
+	Source
+	// WatchPaths returns the files the source is read from
+	WatchPaths() []string
+}
+
+// WatchedModTime returns the latest modification time of the files a source
+// is read from, or the zero time if it isn't a WatchedSource or none of its
+// files exist
+func WatchedModTime(source Source) time.Time {
+	watched, ok := source.(WatchedSource)
+	if !ok {
+		return time.Time{}
+	}
+	var latest time.Time
+	for _, path := range watched.WatchPaths() {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// SourceFactory creates the sources configured in cfg. A factory may return
+// several sources, e.g. one per SSH config file, or none.
+type SourceFactory func(cfg *models.Config) []Source
+
+// sourceFactories holds the registered source factories
+var sourceFactories []SourceFactory
+
+// RegisterSource registers a factory for a kind of host source. Sources are
+// normally registered from init functions.
+func RegisterSource(factory SourceFactory) {
+	sourceFactories = append(sourceFactories, factory)
+}
+
+// Sources returns the sources configured in cfg ordered by precedence,
+// including disabled ones. Sources listed in the merge order come first, in
+// that order; the rest follow by their own priority.
+func Sources(cfg *models.Config) []Source {
+	var sources []Source
+	for _, factory := range sourceFactories {
+		sources = append(sources, factory(cfg)...)
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		rankI, rankJ := cfg.Merge.Rank(sources[i].Name()), cfg.Merge.Rank(sources[j].Name())
+		if rankI != rankJ {
+			// Unlisted sources (rank -1) sort after listed ones
+			if rankI == -1 || rankJ == -1 {
+				return rankJ == -1
+			}
+			return rankI < rankJ
+		}
+		return sources[i].Priority() < sources[j].Priority()
+	})
+	return sources
+}
+
+// mergeHosts combines the hosts of sources given in precedence order. The
+// first source defining an alias provides the host; every source defining
+// it is listed in AvailableIn and its version kept in Variants. If the merge
+// config takes fields from other sources, see mergeFields.
+func mergeHosts(sources []SourceResult, merge models.MergeConfig) []models.Host {
+	hostMap := make(map[string]*models.Host)
+	var order []string
+
+	for _, source := range sources {
+		for _, host := range source.Hosts {
+			host.Source = source.Name
+			host.AvailableIn = nil
+			host.Variants = nil
+			variant := host
+
+			if existing, found := hostMap[host.Alias]; found {
+				if _, seen := existing.Variants[source.Name]; seen {
+					// Duplicate alias within one source: the first one counts
+					continue
+				}
+				// Shadowed by a higher priority source - record availability
+				existing.AvailableIn = append(existing.AvailableIn, source.Name)
+				existing.Variants[source.Name] = &variant
+				continue
+			}
+
+			host.AvailableIn = []string{source.Name}
+			host.Variants = map[string]*models.Host{source.Name: &variant}
+			hostMap[host.Alias] = &host
+			order = append(order, host.Alias)
+		}
+	}
+
+	hosts := make([]models.Host, 0, len(order))
+	for _, alias := range order {
+		host := hostMap[alias]
+		if len(host.AvailableIn) > 1 && merge.MergesFields() {
+			mergeFields(host, merge)
+		}
+		hosts = append(hosts, *host)
+	}
+	return hosts
+}
+
+// mergeFields fills the fields of a host defined by several sources according
+// to the merge config. If the result differs from the winning source's host,
+// it replaces the host and is also kept as the MergedSource variant.
+func mergeFields(host *models.Host, merge models.MergeConfig) {
+	merged := *host
+	changed := false
+	hostnameFrom := host // The variant the hostname is taken from
+
+	for _, field := range models.MergeableFields() {
+		strategy := merge.FieldStrategy(field)
+		if strategy == models.MergeWinner || models.FollowsHostname(field) {
+			continue
+		}
+
+		// A named source is preferred; field-merge (and a preferred source
+		// that doesn't set the field) takes the first source setting it
+		var from *models.Host
+		if strategy != models.MergeFieldMerge {
+			from = firstVariantWithField(host, field, func(source string) bool {
+				return models.SourceMatches(strategy, source)
+			})
+		}
+		if from == nil {
+			from = firstVariantWithField(host, field, func(string) bool { return true })
+		}
+		if from != nil && merged.CopyField(field, from) {
+			changed = true
+		}
+		if from != nil && field == "hostname" {
+			hostnameFrom = from
+		}
+	}
+
+	// How to reach the host goes with its hostname
+	for _, field := range models.MergeableFields() {
+		if models.FollowsHostname(field) && merged.CopyField(field, hostnameFrom) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+	variant := merged
+	variant.Variants = nil
+	merged.Variants[models.MergedSource] = &variant
+	*host = merged
+}
+
+// firstVariantWithField returns the highest priority variant of host from a
+// source accepted by match that sets field
+func firstVariantWithField(host *models.Host, field string, match func(source string) bool) *models.Host {
+	for _, source := range host.AvailableIn {
+		variant := host.Variants[source]
+		if variant != nil && match(source) && variant.FieldIsSet(field) {
+			return variant
+		}
+	}
+	return nil
+}
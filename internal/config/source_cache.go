@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sshbuddy/pkg/models"
+	"sync"
+	"time"
+)
+
+// sourceCacheFile stores the last successful result of each offline source
+const sourceCacheFile = "sources-cache.json"
+
+// OfflineSource is implemented by sources that may be unreachable, such as
+// remote servers. Their last successful result is cached and used in place
+// of the source when loading it fails.
+type OfflineSource interface {
+	Source
+	// CacheKey identifies where the hosts come from (e.g. the server URL), so
+	// a cached result is not used after the source has been reconfigured
+	CacheKey() string
+}
+
+// sourceCacheEntry is the cached result of one source
+type sourceCacheEntry struct {
+	Key     string        `json:"key"`
+	SavedAt time.Time     `json:"savedAt"`
+	Hosts   []models.Host `json:"hosts"`
+}
+
+// sourceCacheMu serializes updates of the cache file by sources loading
+// concurrently
+var sourceCacheMu sync.Mutex
+
+// cachedSourceHosts returns the cached result of a source, if there is one
+// for the same cache key
+func cachedSourceHosts(name, key string) (sourceCacheEntry, bool) {
+	sourceCacheMu.Lock()
+	defer sourceCacheMu.Unlock()
+
+	entry, ok := loadSourceCache()[name]
+	if !ok || entry.Key != key {
+		return sourceCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// cacheSourceHosts stores the hosts a source just loaded
+func cacheSourceHosts(name, key string, hosts []models.Host) error {
+	sourceCacheMu.Lock()
+	defer sourceCacheMu.Unlock()
+
+	cache := loadSourceCache()
+	cache[name] = sourceCacheEntry{Key: key, SavedAt: time.Now(), Hosts: hosts}
+	return saveSourceCache(cache)
+}
+
+// loadSourceCache reads the source cache, returning an empty cache on any error
+func loadSourceCache() map[string]sourceCacheEntry {
+	cache := make(map[string]sourceCacheEntry)
+
+	path, err := getCachePath(sourceCacheFile)
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logError("Failed to parse source cache", err)
+		return make(map[string]sourceCacheEntry)
+	}
+	return cache
+}
+
+// saveSourceCache writes the source cache
+func saveSourceCache(cache map[string]sourceCacheEntry) error {
+	path, err := getCachePath(sourceCacheFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+// fakeSource is a source with fixed hosts, or a fixed error
+type fakeSource struct {
+	name     string
+	priority int
+	disabled bool
+	hosts    []models.Host
+	err      error
+	loaded   *bool // Set when Load is called
+}
+
+func (s fakeSource) Name() string  { return s.name }
+func (s fakeSource) Priority() int { return s.priority }
+func (s fakeSource) Enabled() bool { return !s.disabled }
+
+func (s fakeSource) Load(ctx context.Context) ([]models.Host, error) {
+	if s.loaded != nil {
+		*s.loaded = true
+	}
+	return s.hosts, s.err
+}
+
+// useSources replaces the registered sources for the duration of a test
+func useSources(t *testing.T, sources ...Source) {
+	t.Helper()
+	saved := sourceFactories
+	sourceFactories = []SourceFactory{func(*models.Config) []Source { return sources }}
+	t.Cleanup(func() { sourceFactories = saved })
+}
+
+func TestSourcesOrder(t *testing.T) {
+	useSources(t,
+		fakeSource{name: "termix", priority: 200},
+		fakeSource{name: "ssh-config:/b", priority: 101},
+		fakeSource{name: "manual", priority: 0},
+		fakeSource{name: "ssh-config", priority: 100},
+		fakeSource{name: "exec:inv", priority: 300},
+	)
+
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{"by priority", nil, []string{"manual", "ssh-config", "ssh-config:/b", "termix", "exec:inv"}},
+		{"listed first", []string{"termix"}, []string{"termix", "manual", "ssh-config", "ssh-config:/b", "exec:inv"}},
+		{"kind matches every file", []string{"ssh-config", "sshbuddy"}, []string{"ssh-config", "ssh-config:/b", "manual", "termix", "exec:inv"}},
+		{"exact source", []string{"exec:inv", "ssh-config:/b"}, []string{"exec:inv", "ssh-config:/b", "manual", "ssh-config", "termix"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &models.Config{Merge: models.MergeConfig{Order: tt.order}}
+			var got []string
+			for _, source := range Sources(cfg) {
+				got = append(got, source.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// mergedHost is the expected outcome of merging one alias
+type mergedHost struct {
+	alias       string
+	source      string
+	hostname    string
+	availableIn []string
+	variants    map[string]string // Source to the variant's hostname
+}
+
+func TestMergeHosts(t *testing.T) {
+	host := func(alias, hostname string) models.Host {
+		return models.Host{Alias: alias, Hostname: hostname}
+	}
+
+	tests := []struct {
+		name    string
+		sources []SourceResult
+		merge   models.MergeConfig
+		want    []mergedHost // In output order
+	}{
+		{
+			name: "first source wins",
+			sources: []SourceResult{
+				{Name: "manual", Hosts: []models.Host{host("web", "manual.example")}},
+				{Name: "ssh-config", Hosts: []models.Host{host("web", "config.example"), host("db", "db.example")}},
+				{Name: "termix", Hosts: []models.Host{host("web", "termix.example")}},
+			},
+			want: []mergedHost{
+				{"web", "manual", "manual.example", []string{"manual", "ssh-config", "termix"},
+					map[string]string{"manual": "manual.example", "ssh-config": "config.example", "termix": "termix.example"}},
+				{"db", "ssh-config", "db.example", []string{"ssh-config"},
+					map[string]string{"ssh-config": "db.example"}},
+			},
+		},
+		{
+			name: "available in follows source order, not host order",
+			sources: []SourceResult{
+				{Name: "ssh-config", Hosts: []models.Host{host("b", "b1"), host("a", "a1")}},
+				{Name: "termix", Hosts: []models.Host{host("a", "a2")}},
+				{Name: "exec:inv", Hosts: []models.Host{host("a", "a3"), host("b", "b3")}},
+			},
+			want: []mergedHost{
+				{"b", "ssh-config", "b1", []string{"ssh-config", "exec:inv"},
+					map[string]string{"ssh-config": "b1", "exec:inv": "b3"}},
+				{"a", "ssh-config", "a1", []string{"ssh-config", "termix", "exec:inv"},
+					map[string]string{"ssh-config": "a1", "termix": "a2", "exec:inv": "a3"}},
+			},
+		},
+		{
+			name: "same alias twice in one source",
+			sources: []SourceResult{
+				{Name: "manual", Hosts: []models.Host{host("web", "first"), host("web", "second")}},
+				{Name: "termix", Hosts: []models.Host{host("web", "t1"), host("web", "t2")}},
+			},
+			want: []mergedHost{
+				{"web", "manual", "first", []string{"manual", "termix"},
+					map[string]string{"manual": "first", "termix": "t1"}},
+			},
+		},
+		{
+			name: "field merge adds a merged variant",
+			sources: []SourceResult{
+				{Name: "manual", Hosts: []models.Host{{Alias: "web", Hostname: "10.0.0.1"}}},
+				{Name: "termix", Hosts: []models.Host{{Alias: "web", Hostname: "web.example", DefaultPath: "/srv"}}},
+			},
+			merge: models.MergeConfig{Strategy: models.MergeFieldMerge},
+			want: []mergedHost{
+				{"web", "manual", "10.0.0.1", []string{"manual", "termix"},
+					map[string]string{"manual": "10.0.0.1", "termix": "web.example", models.MergedSource: "10.0.0.1"}},
+			},
+		},
+		{
+			name: "failed source without hosts",
+			sources: []SourceResult{
+				{Name: "manual", Hosts: []models.Host{host("web", "10.0.0.1")}},
+				{Name: "termix", Err: errors.New("unreachable")},
+			},
+			want: []mergedHost{
+				{"web", "manual", "10.0.0.1", []string{"manual"}, map[string]string{"manual": "10.0.0.1"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeHosts(tt.sources, tt.merge)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d hosts, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				h := got[i]
+				if h.Alias != want.alias || h.Source != want.source || h.Hostname != want.hostname {
+					t.Errorf("host %d = %s from %s (%s), want %s from %s (%s)",
+						i, h.Alias, h.Source, h.Hostname, want.alias, want.source, want.hostname)
+				}
+				if !reflect.DeepEqual(h.AvailableIn, want.availableIn) {
+					t.Errorf("%s: available in %v, want %v", h.Alias, h.AvailableIn, want.availableIn)
+				}
+				variants := make(map[string]string)
+				for source, variant := range h.Variants {
+					variants[source] = variant.Hostname
+					if variant.Variants != nil {
+						t.Errorf("%s: variant %s has variants of its own", h.Alias, source)
+					}
+				}
+				if !reflect.DeepEqual(variants, want.variants) {
+					t.Errorf("%s: variants %v, want %v", h.Alias, variants, want.variants)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeHostsFieldMergeValues(t *testing.T) {
+	sources := []SourceResult{
+		{Name: "manual", Hosts: []models.Host{{Alias: "web", Hostname: "10.0.0.1", Tags: []string{"prod"}}}},
+		{Name: "termix", Hosts: []models.Host{{Alias: "web", Hostname: "web.example", User: "deploy", DefaultPath: "/srv", Tags: []string{"termix"}}}},
+	}
+
+	tests := []struct {
+		name  string
+		merge models.MergeConfig
+		want  models.Host
+	}{
+		{"winner", models.MergeConfig{}, models.Host{Hostname: "10.0.0.1", Tags: []string{"prod"}}},
+		{"field merge", models.MergeConfig{Strategy: models.MergeFieldMerge},
+			models.Host{Hostname: "10.0.0.1", User: "deploy", DefaultPath: "/srv", Tags: []string{"prod"}}},
+		{"preferred source per field", models.MergeConfig{Fields: map[string]string{"hostname": "termix"}},
+			models.Host{Hostname: "web.example", Tags: []string{"prod"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := mergeHosts(sources, tt.merge)[0]
+			got := models.Host{Hostname: h.Hostname, User: h.User, DefaultPath: h.DefaultPath, Tags: h.Tags}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if manual := h.Variants["manual"]; manual.User != "" || manual.DefaultPath != "" {
+				t.Errorf("merging changed the manual variant: %+v", manual)
+			}
+		})
+	}
+}
+
+func TestMergeHostsConnectModeFollowsHostname(t *testing.T) {
+	sources := []SourceResult{
+		{Name: "manual", Hosts: []models.Host{{Alias: "web", Hostname: "10.0.0.1", User: "admin"}}},
+		{Name: "docker", Hosts: []models.Host{{Alias: "web", Hostname: "web-1", ConnectMode: models.ConnectModeDocker, PingMethod: "tcp"}}},
+	}
+
+	tests := []struct {
+		name         string
+		merge        models.MergeConfig
+		wantHostname string
+		wantMode     string
+		wantPing     string
+	}{
+		{"field merge", models.MergeConfig{Strategy: models.MergeFieldMerge}, "10.0.0.1", "", ""},
+		{"merge overrides are ignored", models.MergeConfig{Fields: map[string]string{"connect_mode": "docker", "ping_method": "field-merge"}}, "10.0.0.1", "", ""},
+		{"hostname from the container", models.MergeConfig{Fields: map[string]string{"hostname": "docker"}}, "web-1", models.ConnectModeDocker, "tcp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := mergeHosts(sources, tt.merge)[0]
+			if h.Hostname != tt.wantHostname || h.ConnectMode != tt.wantMode || h.PingMethod != tt.wantPing {
+				t.Errorf("got %s with connect mode %q and ping method %q, want %s with %q and %q",
+					h.Hostname, h.ConnectMode, h.PingMethod, tt.wantHostname, tt.wantMode, tt.wantPing)
+			}
+			if h.User != "admin" {
+				t.Errorf("user %q, want the manual user", h.User)
+			}
+		})
+	}
+}
+
+func TestLoadConfigSkipsDisabledAndFailingSources(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	disabledLoaded := false
+	useSources(t,
+		fakeSource{name: "manual", priority: 0, hosts: []models.Host{{Alias: "web", Hostname: "10.0.0.1"}}},
+		fakeSource{name: "exec:off", priority: 300, disabled: true, loaded: &disabledLoaded,
+			hosts: []models.Host{{Alias: "web", Hostname: "off.example"}, {Alias: "off", Hostname: "off"}}},
+		fakeSource{name: "exec:broken", priority: 301, err: errors.New("exit status 1")},
+		fakeSource{name: "exec:inv", priority: 302, hosts: []models.Host{{Alias: "job", Hostname: "10.0.0.2"}}},
+	)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if disabledLoaded {
+		t.Error("a disabled source was loaded")
+	}
+
+	var aliases []string
+	for _, host := range cfg.Hosts {
+		aliases = append(aliases, host.Alias)
+		if host.Alias == "web" && !reflect.DeepEqual(host.AvailableIn, []string{"manual"}) {
+			t.Errorf("web available in %v, want only manual", host.AvailableIn)
+		}
+	}
+	if !reflect.DeepEqual(aliases, []string{"job", "web"}) {
+		t.Errorf("got hosts %v, want [job web]", aliases)
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"sshbuddy/internal/ssh"
 	"sshbuddy/internal/termix"
 	"sshbuddy/pkg/models"
 )
 
-func GetDataPath() (string, error) {
+// GetDataDir returns the sshbuddy config directory, creating it if needed
+func GetDataDir() (string, error) {
 	// Use XDG_CONFIG_HOME if set, otherwise default to ~/.config
 	configDir := os.Getenv("XDG_CONFIG_HOME")
 	if configDir == "" {
@@ -27,10 +30,39 @@ func GetDataPath() (string, error) {
 		return "", err
 	}
 
+	return sshbuddyDir, nil
+}
+
+// expandHomePath expands a leading ~/ in path to the home directory
+func expandHomePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, path[2:])
+		}
+	}
+	return path
+}
+
+func GetDataPath() (string, error) {
+	sshbuddyDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+
 	return filepath.Join(sshbuddyDir, "config.json"), nil
 }
 
-// LoadConfig is now in sources.go
+// getCachePath returns the path of a cache file in the sshbuddy config directory
+func getCachePath(name string) (string, error) {
+	sshbuddyDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(sshbuddyDir, name), nil
+}
+
+// LoadConfig is in loader.go
 
 func SaveConfig(config *models.Config) error {
 	path, err := GetDataPath()
@@ -41,40 +73,37 @@ func SaveConfig(config *models.Config) error {
 	// Only save manual hosts (not SSH config or termix hosts)
 	// But save favorites for all hosts
 	saveConfig := &models.Config{
-		Theme:     config.Theme,
-		Sources:   config.Sources,
-		Termix:    config.Termix,
-		SSH:       config.SSH,
-		Hosts:     []models.Host{},
-		Favorites: make(map[string]bool),
+		Theme:      config.Theme,
+		Sources:    config.Sources,
+		Termix:     config.Termix,
+		SSH:        config.SSH,
+		Ping:       config.Ping,
+		Merge:      config.Merge,
+		KnownHosts: config.KnownHosts,
+		Exec:       config.Exec,
+		HTTP:       config.HTTP,
+		Ansible:    config.Ansible,
+		Terraform:  config.Terraform,
+		Vagrant:    config.Vagrant,
+		Docker:     config.Docker,
+		Hosts:      []models.Host{},
+		Favorites:  make(map[string]bool),
 	}
 
-	for _, host := range config.Hosts {
-		shouldSave := false
-
-		// Check if primary source is manual
-		if host.Source != "ssh-config" && host.Source != "termix" {
-			shouldSave = true
-		}
-
-		// Check if it's available in manual source (even if shadowed/merged)
-		if !shouldSave {
-			for _, src := range host.AvailableIn {
-				if src == "manual" || src == "sshbuddy" {
-					shouldSave = true
-					break
-				}
-			}
+	// Keep the favorites of hosts that aren't loaded, e.g. when saving a raw
+	// config without the hosts of other sources
+	for alias, favorite := range config.Favorites {
+		if favorite {
+			saveConfig.Favorites[alias] = true
 		}
+	}
 
-		if shouldSave {
-			// Create a copy to save as manual host
-			hostToSave := host
-			hostToSave.Source = "manual"
+	for _, host := range config.Hosts {
+		if manual, ok := manualVersion(host); ok {
+			manual.Source = "manual"
 			// Don't save runtime fields
-			hostToSave.AvailableIn = nil
-
-			saveConfig.Hosts = append(saveConfig.Hosts, hostToSave)
+			manual.AvailableIn = nil
+			saveConfig.Hosts = append(saveConfig.Hosts, manual)
 		}
 
 		// Save favorite status for all hosts (including external sources)
@@ -91,6 +120,43 @@ func SaveConfig(config *models.Config) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// UpdateSSHConfigHost writes an edited host back to the SSH config file that
+// provides the given ssh-config source
+func UpdateSSHConfigHost(config *models.Config, source string, original, updated models.Host) error {
+	path, ok := config.SSH.PathForSource(source)
+	if !ok {
+		return fmt.Errorf("unknown SSH config source %q", source)
+	}
+	return ssh.UpdateHostInConfig(path, original, updated)
+}
+
+// manualVersion returns the version of a host stored in sshbuddy's own config,
+// if it has one. Merged hosts carry it as their manual variant, so values
+// merged in from other sources aren't saved; hosts of a raw config aren't
+// merged and are manual unless they name another source.
+func manualVersion(host models.Host) (models.Host, bool) {
+	if len(host.AvailableIn) == 0 {
+		return host, host.Source == "" || isManualSource(host.Source)
+	}
+	for _, src := range host.AvailableIn {
+		if !isManualSource(src) {
+			continue
+		}
+		if variant := host.Variants[src]; variant != nil {
+			manual := *variant
+			manual.Favorite = host.Favorite
+			return manual, true
+		}
+		return host, true
+	}
+	return models.Host{}, false
+}
+
+// isManualSource reports whether source names sshbuddy's own config
+func isManualSource(source string) bool {
+	return source == "manual" || source == "sshbuddy"
+}
+
 // logError logs errors to a debug file for troubleshooting
 func logError(context string, err error) {
 	logPath := "/tmp/sshbuddy-debug.log"
@@ -170,3 +236,37 @@ func AuthenticateTermix(username, password string) error {
 
 	return SaveConfig(config)
 }
+
+// saveTermixToken stores a refreshed Termix JWT. Sources load in the
+// background, so the config file is read again and only the token changed;
+// saving the config the source was created from would revert hosts edited
+// meanwhile.
+func saveTermixToken(jwt string, expiry int64) error {
+	config, err := LoadConfigRaw()
+	if err != nil {
+		return err
+	}
+	config.Termix.JWT = jwt
+	config.Termix.JWTExpiry = expiry
+	return SaveConfig(config)
+}
+
+// SaveFavorite stores whether the host with alias is a favorite. Like
+// saveTermixToken, it reads the config file again and changes only the
+// favorites, so that a token refreshed or hosts edited since the caller
+// loaded its config are not reverted.
+func SaveFavorite(alias string, favorite bool) error {
+	config, err := LoadConfigRaw()
+	if err != nil {
+		return err
+	}
+	if config.Favorites == nil {
+		config.Favorites = make(map[string]bool)
+	}
+	if favorite {
+		config.Favorites[alias] = true
+	} else {
+		delete(config.Favorites, alias)
+	}
+	return SaveConfig(config)
+}
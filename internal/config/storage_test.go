@@ -0,0 +1,115 @@
+package config
+
+import (
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestSaveConfigSavesOnlyManualVersions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	manualWeb := models.Host{Alias: "web", Hostname: "10.0.0.1", User: "admin", Source: "manual"}
+	mergedWeb := manualWeb
+	mergedWeb.Port = "2222" // Merged in from the exec source
+	mergedWeb.AvailableIn = []string{"manual", "exec:inv"}
+	mergedWeb.Variants = map[string]*models.Host{
+		"manual":   &manualWeb,
+		"exec:inv": {Alias: "web", Hostname: "10.0.0.1", Port: "2222"},
+	}
+
+	cfg := &models.Config{
+		Hosts: []models.Host{
+			mergedWeb,
+			{Alias: "job", Hostname: "10.0.0.2", Source: "exec:inv", AvailableIn: []string{"exec:inv"}, Favorite: true},
+			{Alias: "app", Hostname: "app", Source: "docker", AvailableIn: []string{"docker"}},
+			{Alias: "seen", Hostname: "seen", Source: models.KnownHostsSource, AvailableIn: []string{models.KnownHostsSource}},
+			{Alias: "prod", Hostname: "prod", Source: "ssh-config", AvailableIn: []string{"ssh-config", "manual"},
+				Variants: map[string]*models.Host{"manual": {Alias: "prod", Hostname: "prod.internal", Source: "manual"}}},
+		},
+	}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadConfigRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Hosts) != 2 {
+		t.Fatalf("saved %d hosts, want web and prod: %+v", len(saved.Hosts), saved.Hosts)
+	}
+	if got := saved.Hosts[0]; got.Alias != "web" || got.Port != "" || got.Source != "manual" || got.AvailableIn != nil {
+		t.Errorf("web saved as %+v, want its manual version", got)
+	}
+	if got := saved.Hosts[1]; got.Alias != "prod" || got.Hostname != "prod.internal" {
+		t.Errorf("prod saved as %+v, want its manual version", got)
+	}
+	if !saved.Favorites["job"] {
+		t.Error("favorite of an exec host wasn't saved")
+	}
+}
+
+func TestSaveConfigRawHosts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := &models.Config{
+		Hosts: []models.Host{
+			{Alias: "a", Hostname: "a", Source: "manual"},
+			{Alias: "b", Hostname: "b"},
+			{Alias: "c", Hostname: "c", Source: "termix"},
+		},
+	}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadConfigRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var aliases []string
+	for _, host := range saved.Hosts {
+		aliases = append(aliases, host.Alias)
+	}
+	if len(aliases) != 2 || aliases[0] != "a" || aliases[1] != "b" {
+		t.Errorf("saved %v, want [a b]", aliases)
+	}
+}
+
+func TestSaveFavoriteKeepsOtherChanges(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A snapshot taken at startup, before a source refreshed the token
+	startup := &models.Config{
+		Hosts:  []models.Host{{Alias: "web", Hostname: "10.0.0.1", User: "admin", Source: "manual"}},
+		Termix: models.TermixConfig{JWT: "old", JWTExpiry: 1},
+	}
+	if err := SaveConfig(startup); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveTermixToken("new", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveFavorite("web", true); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadConfigRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Termix.JWT != "new" || saved.Termix.JWTExpiry != 2 {
+		t.Errorf("token reverted to %q (%d)", saved.Termix.JWT, saved.Termix.JWTExpiry)
+	}
+	if !saved.Favorites["web"] || len(saved.Hosts) != 1 {
+		t.Errorf("got favorites %v and %d hosts, want web as favorite", saved.Favorites, len(saved.Hosts))
+	}
+
+	if err := SaveFavorite("web", false); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := LoadConfigRaw(); saved.Favorites["web"] {
+		t.Error("web is still a favorite")
+	}
+}
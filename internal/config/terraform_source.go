@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sshbuddy/internal/terraform"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// terraformStateFile is read when a Terraform source's path is a directory
+const terraformStateFile = "terraform.tfstate"
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		var sources []Source
+		for i, terraformCfg := range cfg.Terraform {
+			sources = append(sources, terraformSource{cfg: terraformCfg, index: i})
+		}
+		return sources
+	})
+}
+
+// terraformSource provides the instances of a Terraform state
+type terraformSource struct {
+	cfg   models.TerraformSourceConfig
+	index int // Position in Config.Terraform
+}
+
+func (s terraformSource) Name() string  { return s.cfg.SourceName() }
+func (s terraformSource) Priority() int { return priorityTerraform + s.index }
+func (s terraformSource) Enabled() bool { return s.cfg.Enabled && strings.TrimSpace(s.cfg.Path) != "" }
+
+// WatchPaths returns the state file, so hosts follow `terraform apply`
+func (s terraformSource) WatchPaths() []string {
+	return []string{s.statePath()}
+}
+
+// statePath resolves the configured path to the state file. A leading ~/ is
+// expanded, and a directory stands for the terraform.tfstate inside it.
+func (s terraformSource) statePath() string {
+	path := expandHomePath(strings.TrimSpace(s.cfg.Path))
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, terraformStateFile)
+	}
+	return path
+}
+
+// Load reads the state and maps its instances to hosts. Instances without an
+// address are logged and left out.
+func (s terraformSource) Load(ctx context.Context) ([]models.Host, error) {
+	path := s.statePath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name(), err)
+	}
+	instances, err := terraform.ParseState(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", s.Name(), path, err)
+	}
+
+	hosts, skipped, err := terraform.MapHosts(s.cfg, instances)
+	if err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		logError(fmt.Sprintf("Source %s", s.Name()), fmt.Errorf("skipped %d instances without an address", skipped))
+	}
+	return hosts, nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sshbuddy/internal/ssh"
+	"sshbuddy/pkg/models"
+	"strings"
+	"sync"
+)
+
+func init() {
+	RegisterSource(func(cfg *models.Config) []Source {
+		return []Source{vagrantSource{cfg.Vagrant}}
+	})
+}
+
+// vagrantSource provides the machines of the Vagrant projects in the
+// configured directories, as reported by `vagrant ssh-config`
+type vagrantSource struct {
+	cfg models.VagrantSourceConfig
+}
+
+func (s vagrantSource) Name() string  { return models.VagrantSource }
+func (s vagrantSource) Priority() int { return priorityVagrant }
+func (s vagrantSource) Enabled() bool { return s.cfg.Enabled && len(s.cfg.Dirs) > 0 }
+
+// projects returns the Vagrant projects to ask for their machines: each
+// configured directory with a Vagrantfile, or else its subdirectories with
+// one. Projects that were never brought up have no machines and are left out.
+func (s vagrantSource) projects() []string {
+	var projects []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if seen[dir] || !isDir(filepath.Join(dir, ".vagrant", "machines")) {
+			return
+		}
+		seen[dir] = true
+		projects = append(projects, dir)
+	}
+
+	for _, dir := range s.cfg.Dirs {
+		dir = expandHomePath(strings.TrimSpace(dir))
+		if dir == "" {
+			continue
+		}
+		if isFile(filepath.Join(dir, "Vagrantfile")) {
+			add(dir)
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			logError(fmt.Sprintf("Source %s", s.Name()), err)
+			continue
+		}
+		for _, entry := range entries {
+			sub := filepath.Join(dir, entry.Name())
+			if entry.IsDir() && isFile(filepath.Join(sub, "Vagrantfile")) {
+				add(sub)
+			}
+		}
+	}
+	return projects
+}
+
+// Load runs `vagrant ssh-config` in every project at once. A project whose
+// machines are all down is logged and skipped; the source only fails when no
+// project has a running machine and at least one failed.
+func (s vagrantSource) Load(ctx context.Context) ([]models.Host, error) {
+	projects := s.projects()
+	hosts := make([][]models.Host, len(projects))
+	errs := make([]error, len(projects))
+
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		wg.Add(1)
+		go func(i int, project string) {
+			defer wg.Done()
+			hosts[i], errs[i] = s.loadProject(ctx, project)
+		}(i, project)
+	}
+	wg.Wait()
+
+	var all []models.Host
+	for i := range projects {
+		if errs[i] != nil {
+			logError(fmt.Sprintf("Source %s", s.Name()), errs[i])
+		}
+		all = append(all, hosts[i]...)
+	}
+	if len(all) == 0 {
+		if err := errors.Join(errs...); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name(), err)
+		}
+	}
+	return all, nil
+}
+
+// loadProject returns the running machines of one project. Vagrant exits
+// with an error when any machine is down, but still prints the others, so
+// the output is used whenever it has hosts.
+func (s vagrantSource) loadProject(ctx context.Context, project string) ([]models.Host, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "vagrant", "ssh-config")
+	cmd.Dir = project
+	cmd.WaitDelay = execWaitDelay
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, runErr := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s: vagrant ssh-config timed out after %s", project, s.cfg.Timeout())
+	}
+
+	machines, err := ssh.ParseSSHConfigData(output)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", project, err)
+	}
+	if len(machines) == 0 && runErr != nil {
+		return nil, fmt.Errorf("%s: %w: %s", project, runErr, strings.TrimSpace(stderr.String()))
+	}
+
+	name := filepath.Base(project)
+	hosts := make([]models.Host, 0, len(machines))
+	for _, machine := range machines {
+		host := ssh.ConvertToHost(machine)
+		// Multi-machine projects name each machine; single ones call it default
+		host.Alias = name
+		if machine.Host != "default" {
+			host.Alias = name + "-" + machine.Host
+		}
+		// Vagrant quotes the key path when it contains spaces
+		host.IdentityFile = strings.Trim(host.IdentityFile, `"`)
+		host.Tags = []string{"vagrant", name}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// isFile reports whether path is an existing regular file
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
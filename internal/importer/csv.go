@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// csvColumns maps the accepted header names, lower-cased, to host fields
+var csvColumns = map[string]string{
+	"alias": "alias", "name": "alias", "label": "alias", "session": "alias",
+	"hostname": "hostname", "host": "hostname", "address": "hostname", "ip": "hostname",
+	"user": "user", "username": "user", "login": "user",
+	"port":          "port",
+	"identity_file": "identity_file", "identityfile": "identity_file", "key": "identity_file",
+	"proxy_jump": "proxy_jump", "proxyjump": "proxy_jump", "jump": "proxy_jump",
+	"default_path": "default_path", "path": "default_path",
+	"tags": "tags", "tag": "tags",
+	"group": "group", "folder": "group",
+}
+
+// ParseCSV reads hosts from a spreadsheet saved as CSV. The first row names
+// the columns (see csvColumns; unknown ones are ignored) and must have a
+// hostname column. Commas and semicolons both work as separators. Tags are
+// separated by commas or semicolons within their cell, and a group or folder
+// column adds one tag per level of its path. Rows without an alias are named
+// after their hostname.
+func ParseCSV(data []byte) (Result, error) {
+	text := decodeText(data)
+	header, _, _ := strings.Cut(text, "\n")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';' // Spreadsheets in locales with a decimal comma
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return Result{}, fmt.Errorf("empty CSV file")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if field, ok := csvColumns[name]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["hostname"]; !ok {
+		return Result{}, fmt.Errorf("the first row needs a hostname (or host, address, ip) column")
+	}
+
+	var result Result
+	for n, record := range records[1:] {
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		hostname := cell("hostname")
+		if hostname == "" {
+			if strings.TrimSpace(strings.Join(record, "")) != "" {
+				result.warnf("skipping row %d: no hostname", n+2)
+			}
+			continue
+		}
+		user, hostname := splitUserHost(hostname)
+		if u := cell("user"); u != "" {
+			user = u
+		}
+		alias := cell("alias")
+		if alias == "" {
+			alias = hostname
+		}
+
+		host := models.Host{
+			Alias:        aliasFromName(alias),
+			Hostname:     hostname,
+			User:         user,
+			Port:         cell("port"),
+			IdentityFile: cell("identity_file"),
+			ProxyJump:    cell("proxy_jump"),
+			DefaultPath:  cell("default_path"),
+		}
+		for _, tag := range strings.FieldsFunc(cell("tags"), func(r rune) bool { return r == ',' || r == ';' }) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				host.Tags = append(host.Tags, tag)
+			}
+		}
+		host.Tags = append(host.Tags, folderTags(cell("group"))...)
+		result.Hosts = append(result.Hosts, host)
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		want         []models.Host
+		wantWarnings []string
+	}{
+		{
+			name: "semicolons, BOM and CRLF",
+			data: readSample(t, "hosts.csv"),
+			want: []models.Host{
+				{Alias: "web-1", Hostname: "web.example.com", User: "deploy", Port: "2222", Tags: []string{"prod", "web", "Europe", "Servers"}},
+				{Alias: "10.0.0.5", Hostname: "10.0.0.5", User: "postgres"},
+			},
+			wantWarnings: []string{"skipping row 4: no hostname"},
+		},
+		{
+			name: "commas with quoted cells",
+			data: []byte("hostname,alias,username,port,identity_file,jump,path,tags,extra\n" +
+				"10.0.0.1,web,admin,22,~/.ssh/id,bastion,/srv,\"a, b;c\",ignored\n" +
+				"10.0.0.2\n"),
+			want: []models.Host{
+				{Alias: "web", Hostname: "10.0.0.1", User: "admin", Port: "22", IdentityFile: "~/.ssh/id", ProxyJump: "bastion", DefaultPath: "/srv", Tags: []string{"a", "b", "c"}},
+				{Alias: "10.0.0.2", Hostname: "10.0.0.2"},
+			},
+		},
+		{
+			name: "first matching column wins",
+			data: []byte("Host;IP;Label\nprimary;secondary;x y\n"),
+			want: []models.Host{{Alias: "x-y", Hostname: "primary"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseCSV(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResult(t, result, tt.want, tt.wantWarnings)
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty", "", "empty CSV"},
+		{"no hostname column", "name,user\nweb,admin\n", "needs a hostname"},
+		{"bad quoting", "host\n\"unterminated\n", "invalid CSV"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package importer reads the session exports of other SSH clients as hosts
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"sshbuddy/pkg/models"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{"putty", "termius", "mobaxterm", "csv"}
+
+// Result is what was read from an export
+type Result struct {
+	Hosts    []models.Host
+	Warnings []string // Sessions that were skipped or only partly imported
+}
+
+// warnf records a warning about an entry of the export
+func (r *Result) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Parse reads an export in one of Formats
+func Parse(format string, data []byte) (Result, error) {
+	switch format {
+	case "putty":
+		return ParsePuTTY(data)
+	case "termius":
+		return ParseTermius(data)
+	case "mobaxterm":
+		return ParseMobaXterm(data)
+	case "csv":
+		return ParseCSV(data)
+	default:
+		return Result{}, fmt.Errorf("unknown import format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// decodeText returns the text of an export. Windows tools write UTF-16 (e.g.
+// regedit) or UTF-8 with a byte order mark, which are both handled.
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], false)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], true)
+	}
+	if !utf8.Valid(data) {
+		// Older exports use the Windows code page; keep the ASCII part
+		return strings.ToValidUTF8(string(data), "?")
+	}
+	return string(data)
+}
+
+// decodeUTF16 decodes UTF-16 text without its byte order mark
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// aliasFromName turns a session name into an alias by replacing runs of
+// whitespace with a dash, since aliases are used on the command line
+func aliasFromName(name string) string {
+	return strings.Join(strings.FieldsFunc(name, unicode.IsSpace), "-")
+}
+
+// folderTags turns a folder path such as "Production\Web" into one tag per
+// level
+func folderTags(path string) []string {
+	var tags []string
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '\\' || r == '/' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return tags
+}
+
+// splitUserHost splits "user@host" into its parts
+func splitUserHost(target string) (user, host string) {
+	if i := strings.LastIndex(target, "@"); i != -1 {
+		return target[:i], target[i+1:]
+	}
+	return "", target
+}
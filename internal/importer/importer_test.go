@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+// readSample returns the content of a sample export in testdata
+func readSample(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// checkResult compares the hosts of result and checks that each warning
+// contains the corresponding substring of wantWarnings
+func checkResult(t *testing.T, result Result, want []models.Host, wantWarnings []string) {
+	t.Helper()
+	if !reflect.DeepEqual(result.Hosts, want) {
+		t.Errorf("got hosts\n%+v\nwant\n%+v", result.Hosts, want)
+	}
+	if len(result.Warnings) != len(wantWarnings) {
+		t.Fatalf("got warnings %q, want %d", result.Warnings, len(wantWarnings))
+	}
+	for i, warning := range result.Warnings {
+		if !strings.Contains(warning, wantWarnings[i]) {
+			t.Errorf("warning %q, want one containing %q", warning, wantWarnings[i])
+		}
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"UTF-8", []byte("Host ä"), "Host ä"},
+		{"UTF-8 with BOM", []byte("\xEF\xBB\xBFHost ä"), "Host ä"},
+		{"UTF-16LE", []byte{0xFF, 0xFE, 'H', 0, 0xE4, 0, '\r', 0, '\n', 0}, "Hä\r\n"},
+		{"UTF-16BE", []byte{0xFE, 0xFF, 0, 'H', 0, 0xE4}, "Hä"},
+		{"Windows code page", []byte("caf\xE9"), "caf?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeText(tt.data); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse("securecrt", nil); err == nil || !strings.Contains(err.Error(), "putty, termius, mobaxterm, csv") {
+		t.Errorf("got error %v, want one listing the formats", err)
+	}
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// MobaXterm session settings are "%"-separated fields; these are the ones of
+// SSH sessions that are imported
+const (
+	mobaFieldType        = 0
+	mobaFieldHost        = 1
+	mobaFieldPort        = 2
+	mobaFieldUser        = 3
+	mobaFieldGateway     = 8 // SSH gateway (jump host)
+	mobaFieldGatewayPort = 9
+	mobaFieldGatewayUser = 10
+	mobaFieldKey         = 14
+
+	mobaTypeSSH = "0"
+)
+
+// mobaSessionTypes names the other session types, for warnings
+var mobaSessionTypes = map[string]string{
+	"1": "Telnet", "2": "Rlogin", "3": "Xdmcp", "4": "RDP", "5": "VNC",
+	"6": "FTP", "7": "SFTP", "8": "Serial", "9": "File", "10": "Shell",
+	"11": "Browser", "12": "Mosh", "13": "AWS S3", "14": "WSL",
+}
+
+// ParseMobaXterm reads the SSH sessions of a MobaXterm sessions export
+// (.mxtsessions). The bookmark folder of a session becomes one tag per
+// level, and its SSH gateway the proxy jump. Private keys under the
+// MobaXterm profile directory are mapped to ~/; keys on other Windows paths
+// are left out.
+func ParseMobaXterm(data []byte) (Result, error) {
+	var result Result
+	folder := ""
+	inBookmarks := false
+
+	for _, line := range strings.Split(decodeText(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inBookmarks = strings.HasPrefix(line, "[Bookmarks")
+			folder = ""
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !inBookmarks || !ok {
+			continue
+		}
+		switch name {
+		case "SubRep":
+			folder = value
+			continue
+		case "ImgNum":
+			continue
+		}
+		if host, ok := parseMobaSession(name, value, &result); ok {
+			host.Tags = folderTags(folder)
+			result.Hosts = append(result.Hosts, host)
+		}
+	}
+	return result, nil
+}
+
+// parseMobaSession converts a session line, "#<icon>#<settings>#<terminal>..."
+func parseMobaSession(name, value string, result *Result) (models.Host, bool) {
+	parts := strings.Split(value, "#")
+	if len(parts) < 3 {
+		return models.Host{}, false
+	}
+	fields := strings.Split(parts[2], "%")
+	field := func(i int) string {
+		if i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	if sessionType := field(mobaFieldType); sessionType != mobaTypeSSH {
+		kind := mobaSessionTypes[sessionType]
+		if kind == "" {
+			kind = "type " + sessionType
+		}
+		result.warnf("skipping %q: %s sessions can't be imported", name, kind)
+		return models.Host{}, false
+	}
+	if field(mobaFieldHost) == "" {
+		result.warnf("skipping %q: no host name", name)
+		return models.Host{}, false
+	}
+
+	host := models.Host{
+		Alias:    aliasFromName(name),
+		Hostname: field(mobaFieldHost),
+		User:     field(mobaFieldUser),
+		Port:     field(mobaFieldPort),
+	}
+	if gateway := field(mobaFieldGateway); gateway != "" {
+		if user := field(mobaFieldGatewayUser); user != "" {
+			gateway = user + "@" + gateway
+		}
+		if port := field(mobaFieldGatewayPort); port != "" && port != "22" {
+			gateway += ":" + port
+		}
+		host.ProxyJump = gateway
+	}
+	if key := field(mobaFieldKey); key != "" {
+		if rest, ok := strings.CutPrefix(key, "_ProfileDir_\\"); ok {
+			host.IdentityFile = "~/" + strings.ReplaceAll(rest, "\\", "/")
+		} else {
+			result.warnf("%q: key %s is a Windows path; set the identity file by hand", name, key)
+		}
+	}
+	return host, true
+}
@@ -0,0 +1,28 @@
+package importer
+
+import (
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestParseMobaXterm(t *testing.T) {
+	result, err := ParseMobaXterm(readSample(t, "sessions.mxtsessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, result, []models.Host{
+		{
+			Alias:        "web",
+			Hostname:     "web.example.com",
+			User:         "deploy",
+			Port:         "22",
+			ProxyJump:    "jump@bastion.example.com:2222",
+			IdentityFile: "~/.ssh/id_ed25519",
+		},
+		{Alias: "db-server", Hostname: "10.0.0.5", User: "postgres", Tags: []string{"Production", "DB"}},
+	}, []string{
+		`skipping "win": RDP sessions`,
+		`"db server": key C:\keys\db.pem is a Windows path`,
+	})
+}
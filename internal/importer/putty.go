@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"net/url"
+	"sshbuddy/pkg/models"
+	"strconv"
+	"strings"
+)
+
+// puttySessionsKey is the registry key holding PuTTY's (and KiTTY's) sessions
+const puttySessionsKey = `\Sessions\`
+
+// puttySession is the values of one session key
+type puttySession struct {
+	name   string
+	values map[string]string // Strings unquoted, dwords in decimal
+}
+
+// ParsePuTTY reads the sessions of a PuTTY registry export, made with
+// `reg export HKCU\Software\SimonTatham\PuTTY\Sessions sessions.reg`. KiTTY's
+// Folder value becomes tags. Sessions using other protocols than SSH are
+// skipped, and PuTTY key files (.ppk) aren't taken over since OpenSSH can't
+// read them.
+func ParsePuTTY(data []byte) (Result, error) {
+	var result Result
+	var sessions []*puttySession
+	var current *puttySession
+
+	for _, line := range joinRegContinuations(decodeText(data)) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = nil
+			key := line[1 : len(line)-1]
+			i := strings.Index(key, puttySessionsKey)
+			if strings.HasPrefix(key, "-") || i == -1 {
+				continue
+			}
+			name := key[i+len(puttySessionsKey):]
+			if name == "" || strings.Contains(name, `\`) {
+				continue
+			}
+			if unescaped, err := url.PathUnescape(name); err == nil {
+				name = unescaped
+			}
+			current = &puttySession{name: name, values: make(map[string]string)}
+			sessions = append(sessions, current)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if name, value, ok := parseRegValue(line); ok {
+			current.values[name] = value
+		}
+	}
+
+	for _, session := range sessions {
+		if session.name == "Default Settings" {
+			continue
+		}
+		if host, ok := session.host(&result); ok {
+			result.Hosts = append(result.Hosts, host)
+		}
+	}
+	return result, nil
+}
+
+// host converts a session, recording why it is skipped or incomplete
+func (s *puttySession) host(result *Result) (models.Host, bool) {
+	v := s.values
+	if protocol := v["Protocol"]; protocol != "" && protocol != "ssh" {
+		result.warnf("skipping %q: %s sessions can't be imported", s.name, protocol)
+		return models.Host{}, false
+	}
+
+	user, hostname := splitUserHost(strings.TrimSpace(v["HostName"]))
+	if hostname == "" {
+		result.warnf("skipping %q: no host name", s.name)
+		return models.Host{}, false
+	}
+	if user == "" {
+		user = v["UserName"]
+	}
+
+	host := models.Host{
+		Alias:    aliasFromName(s.name),
+		Hostname: hostname,
+		User:     user,
+		Port:     v["PortNumber"],
+		Tags:     folderTags(v["Folder"]),
+	}
+	if v["AgentFwd"] == "1" {
+		host.ForwardAgent = true
+	}
+	if interval := v["PingIntervalSecs"]; interval != "" && interval != "0" {
+		host.ServerAliveInterval = interval
+	}
+	if keyFile := v["PublicKeyFile"]; keyFile != "" {
+		result.warnf("%q: key %s is a PuTTY key; convert it with `puttygen <key> -O private-openssh` and set it as the identity file", s.name, keyFile)
+	}
+
+	for _, spec := range strings.Split(v["PortForwardings"], ",") {
+		kind, forward, ok := parsePuTTYForward(spec)
+		if !ok {
+			continue
+		}
+		switch kind {
+		case 'L':
+			host.LocalForwards = append(host.LocalForwards, forward)
+		case 'R':
+			host.RemoteForwards = append(host.RemoteForwards, forward)
+		case 'D':
+			host.DynamicForwards = append(host.DynamicForwards, forward)
+		}
+	}
+	return host, true
+}
+
+// parsePuTTYForward converts a PuTTY port forwarding, e.g. "L8080=db:5432"
+// or "4D1080", to its kind and ssh_config syntax ("8080 db:5432")
+func parsePuTTYForward(spec string) (byte, string, bool) {
+	spec = strings.TrimLeft(strings.TrimSpace(spec), "46") // IPv4/IPv6 only
+	if len(spec) < 2 {
+		return 0, "", false
+	}
+	kind := spec[0]
+	listen, target, _ := strings.Cut(spec[1:], "=")
+	switch {
+	case kind == 'D' && listen != "":
+		return kind, listen, true
+	case (kind == 'L' || kind == 'R') && listen != "" && target != "":
+		return kind, listen + " " + target, true
+	}
+	return 0, "", false
+}
+
+// joinRegContinuations splits a registry export into lines, joining values
+// continued with a trailing backslash (long hex values). Other lines end in
+// a quote, a digit or a bracket.
+func joinRegContinuations(text string) []string {
+	var lines []string
+	var pending strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimRight(line, " \t\r")
+		if strings.HasSuffix(trimmed, `\`) {
+			pending.WriteString(strings.TrimSuffix(trimmed, `\`))
+			continue
+		}
+		pending.WriteString(trimmed)
+		lines = append(lines, pending.String())
+		pending.Reset()
+	}
+	return lines
+}
+
+// parseRegValue parses a `"Name"="string"` or `"Name"=dword:0000001b` line.
+// Dwords are returned in decimal; other value types are skipped.
+func parseRegValue(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, `"`) {
+		return "", "", false
+	}
+	name, rest, ok := readRegString(line)
+	if !ok || !strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	rest = rest[1:]
+
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		value, _, ok := readRegString(rest)
+		return name, value, ok
+	case strings.HasPrefix(rest, "dword:"):
+		n, err := strconv.ParseUint(strings.TrimPrefix(rest, "dword:"), 16, 32)
+		if err != nil {
+			return "", "", false
+		}
+		return name, strconv.FormatUint(n, 10), true
+	}
+	return "", "", false
+}
+
+// readRegString reads a quoted string with \\ and \" escapes from the start
+// of s, returning it and the rest of s
+func readRegString(s string) (string, string, bool) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			return sb.String(), s[i+1:], true
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
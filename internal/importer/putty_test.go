@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestParsePuTTY(t *testing.T) {
+	result, err := ParsePuTTY(readSample(t, "putty.reg")) // UTF-16LE, as regedit writes it
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, result, []models.Host{
+		{
+			Alias:               "Prod-Web",
+			Hostname:            "web.example.com",
+			User:                "deploy",
+			Port:                "2338",
+			Tags:                []string{"Production", "Web"},
+			LocalForwards:       []string{"8080 localhost:80"},
+			RemoteForwards:      []string{"9000 127.0.0.1:9000"},
+			DynamicForwards:     []string{"1080"},
+			ForwardAgent:        true,
+			ServerAliveInterval: "30",
+		},
+		{Alias: "db", Hostname: "10.0.0.5", User: "postgres", Port: "22"},
+	}, []string{
+		`"Prod Web": key C:\Users\me\.ssh\web.ppk is a PuTTY key`,
+		`skipping "router": telnet sessions`,
+		`skipping "no host": no host name`,
+	})
+}
+
+func TestParsePuTTYForward(t *testing.T) {
+	tests := []struct {
+		spec    string
+		kind    byte
+		forward string
+		ok      bool
+	}{
+		{"L8080=localhost:80", 'L', "8080 localhost:80", true},
+		{"4L127.0.0.1:8080=db:5432", 'L', "127.0.0.1:8080 db:5432", true},
+		{"R9000=localhost:9000", 'R', "9000 localhost:9000", true},
+		{"6D1080", 'D', "1080", true},
+		{"D1080=ignored", 'D', "1080", true},
+		{"L8080", 0, "", false},
+		{"X1=a:1", 0, "", false},
+		{"", 0, "", false},
+	}
+	for _, tt := range tests {
+		kind, forward, ok := parsePuTTYForward(tt.spec)
+		if kind != tt.kind || forward != tt.forward || ok != tt.ok {
+			t.Errorf("parsePuTTYForward(%q) = %c, %q, %v, want %c, %q, %v", tt.spec, kind, forward, ok, tt.kind, tt.forward, tt.ok)
+		}
+	}
+}
+
+func TestParseRegValue(t *testing.T) {
+	tests := []struct {
+		line        string
+		name, value string
+		ok          bool
+	}{
+		{`"HostName"="web"`, "HostName", "web", true},
+		{`"Folder"="Prod\\Web \"eu\""`, "Folder", `Prod\Web "eu"`, true},
+		{`"PortNumber"=dword:00000922`, "PortNumber", "2338", true},
+		{`"PortNumber"=dword:zz`, "", "", false},
+		{`"Colour0"=hex:bb,bb,bb`, "", "", false},
+		{`@="default"`, "", "", false},
+		{`"Unterminated"="web`, "Unterminated", "", false},
+	}
+	for _, tt := range tests {
+		name, value, ok := parseRegValue(tt.line)
+		if name != tt.name || value != tt.value || ok != tt.ok {
+			t.Errorf("parseRegValue(%q) = %q, %q, %v, want %q, %q, %v", tt.line, name, value, ok, tt.name, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestJoinRegContinuations(t *testing.T) {
+	text := "\"A\"=hex:01,02,\\\r\n  03,04\r\n\"B\"=\"x\"\r\n"
+	got := joinRegContinuations(text)
+	want := []string{"\"A\"=hex:01,02,  03,04", "\"B\"=\"x\"", ""}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// termiusExport is a Termius JSON export: hosts and the groups they are
+// filed in. A bare array of hosts is accepted too.
+type termiusExport struct {
+	Hosts  []termiusHost  `json:"hosts"`
+	Groups []termiusGroup `json:"groups"`
+}
+
+// termiusHost is a host of a Termius export. Connection settings are either
+// on the host itself or in its ssh_config.
+type termiusHost struct {
+	Label     string            `json:"label"`
+	Address   string            `json:"address"`
+	Port      json.Number       `json:"port"`
+	Username  string            `json:"username"`
+	Group     json.RawMessage   `json:"group"` // Group id, label or object
+	Tags      []json.RawMessage `json:"tags"`  // Labels or objects with a label
+	SSHConfig *struct {
+		Port     json.Number `json:"port"`
+		Identity *struct {
+			Username string `json:"username"`
+		} `json:"identity"`
+	} `json:"ssh_config"`
+}
+
+// termiusGroup is a group of hosts, possibly nested in a parent group
+type termiusGroup struct {
+	ID          json.RawMessage `json:"id"`
+	Label       string          `json:"label"`
+	ParentGroup json.RawMessage `json:"parent_group"`
+}
+
+// ParseTermius reads the hosts of a Termius JSON export. The labels of a
+// host's group and its parent groups become tags, after the host's own tags.
+// Keys and passwords can't be exported from Termius and aren't imported.
+func ParseTermius(data []byte) (Result, error) {
+	data = []byte(decodeText(data))
+	var export termiusExport
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &export.Hosts); err != nil {
+			return Result{}, fmt.Errorf("not a Termius export: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &export); err != nil {
+		return Result{}, fmt.Errorf("not a Termius export: %w", err)
+	}
+
+	groups := make(map[string]termiusGroup)
+	for _, group := range export.Groups {
+		groups[rawKey(group.ID)] = group
+	}
+
+	var result Result
+	for _, h := range export.Hosts {
+		name := strings.TrimSpace(h.Label)
+		if name == "" {
+			name = strings.TrimSpace(h.Address)
+		}
+		if strings.TrimSpace(h.Address) == "" {
+			result.warnf("skipping %q: no address", name)
+			continue
+		}
+
+		host := models.Host{
+			Alias:    aliasFromName(name),
+			Hostname: strings.TrimSpace(h.Address),
+			User:     h.Username,
+			Port:     h.Port.String(),
+		}
+		if h.SSHConfig != nil {
+			if host.Port == "" {
+				host.Port = h.SSHConfig.Port.String()
+			}
+			if host.User == "" && h.SSHConfig.Identity != nil {
+				host.User = h.SSHConfig.Identity.Username
+			}
+		}
+
+		for _, tag := range h.Tags {
+			if label := rawLabel(tag); label != "" {
+				host.Tags = append(host.Tags, label)
+			}
+		}
+		host.Tags = append(host.Tags, termiusGroupPath(h.Group, groups)...)
+		result.Hosts = append(result.Hosts, host)
+	}
+	return result, nil
+}
+
+// termiusGroupPath returns the labels of a group and its parents, outermost
+// first. The group is given by id, by label or as an object.
+func termiusGroupPath(ref json.RawMessage, groups map[string]termiusGroup) []string {
+	var path []string
+	seen := make(map[string]bool)
+	for len(ref) > 0 && !seen[string(ref)] {
+		seen[string(ref)] = true
+
+		var group termiusGroup
+		if bytes.HasPrefix(bytes.TrimSpace(ref), []byte("{")) {
+			if json.Unmarshal(ref, &group) != nil {
+				break
+			}
+		} else if found, ok := groups[rawKey(ref)]; ok {
+			group = found
+		} else if json.Unmarshal(ref, &group.Label) != nil {
+			break // An unknown id
+		}
+
+		if label := strings.TrimSpace(group.Label); label != "" {
+			path = append([]string{label}, path...)
+		}
+		ref = group.ParentGroup
+	}
+	return path
+}
+
+// rawKey returns a JSON string or number as a string, and "" for null
+func rawKey(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// rawLabel returns a JSON string, or the label of a JSON object
+func rawLabel(raw json.RawMessage) string {
+	var labelled struct {
+		Label string `json:"label"`
+	}
+	if json.Unmarshal(raw, &labelled) == nil {
+		return strings.TrimSpace(labelled.Label)
+	}
+	return strings.TrimSpace(rawKey(raw))
+}
@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestParseTermius(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		want         []models.Host
+		wantWarnings []string
+	}{
+		{
+			name: "export with groups",
+			data: readSample(t, "termius.json"),
+			want: []models.Host{
+				{Alias: "Web-1", Hostname: "web1.example.com", User: "deploy", Port: "2222", Tags: []string{"prod", "web", "Servers", "Europe"}},
+				{Alias: "db", Hostname: "10.0.0.5", User: "postgres", Port: "22", Tags: []string{"Ad hoc"}},
+			},
+			wantWarnings: []string{`skipping "broken": no address`},
+		},
+		{
+			name: "bare host array with a group label",
+			data: []byte("\xEF\xBB\xBF[{\"label\": \"\", \"address\": \"10.0.0.9\", \"group\": \"Lab\"}]"),
+			want: []models.Host{{Alias: "10.0.0.9", Hostname: "10.0.0.9", Tags: []string{"Lab"}}},
+		},
+		{
+			name: "cyclic groups",
+			data: []byte(`{"hosts": [{"label": "a", "address": "a", "group": "g1"}],
+				"groups": [{"id": "g1", "label": "One", "parent_group": "g2"}, {"id": "g2", "label": "Two", "parent_group": "g1"}]}`),
+			want: []models.Host{{Alias: "a", Hostname: "a", Tags: []string{"Two", "One"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseTermius(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResult(t, result, tt.want, tt.wantWarnings)
+		})
+	}
+
+	if _, err := ParseTermius([]byte("<html>")); err == nil {
+		t.Error("expected an error for a file that isn't JSON")
+	}
+}
@@ -0,0 +1,180 @@
+// Package inventory fetches host lists that teams publish as JSON over HTTP
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sshbuddy/pkg/models"
+	"strconv"
+	"strings"
+)
+
+// Snapshot is the result of a fetch, along with the validators that make
+// the next request for the same list conditional
+type Snapshot struct {
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"lastModified,omitempty"`
+	Hosts        []models.Host `json:"hosts"`
+	Skipped      int           `json:"-"` // Entries without an alias or hostname
+	NotModified  bool          `json:"-"` // The server reported the list as unchanged
+}
+
+// Client fetches the host list of one HTTP source
+type Client struct {
+	cfg    models.HTTPSourceConfig
+	client *http.Client
+}
+
+// NewClient creates a client for the HTTP source configured in cfg
+func NewClient(cfg models.HTTPSourceConfig) *Client {
+	return &Client{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout(),
+		},
+	}
+}
+
+// FetchHosts fetches and maps the host list. If previous holds the result of
+// an earlier fetch, the request is made conditional on its ETag and
+// Last-Modified date, and previous is returned when the list is unchanged.
+func (c *Client) FetchHosts(ctx context.Context, previous *Snapshot) (*Snapshot, error) {
+	name := c.cfg.SourceName()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create request (check url): %w", name, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	} else if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+	if previous != nil {
+		if previous.ETag != "" {
+			req.Header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed (check url and network): %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && previous != nil {
+		unchanged := *previous
+		unchanged.NotModified = true
+		return &unchanged, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		bodyPreview := string(body)
+		if len(bodyPreview) > 200 {
+			bodyPreview = bodyPreview[:200] + "..."
+		}
+		return nil, fmt.Errorf("%s: server returned status %d: %s", name, resp.StatusCode, bodyPreview)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber() // Keep ports and IDs as written
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s: response is not valid JSON: %w", name, err)
+	}
+
+	snapshot, err := MapHosts(c.cfg, doc)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ETag = resp.Header.Get("ETag")
+	snapshot.LastModified = resp.Header.Get("Last-Modified")
+	return snapshot, nil
+}
+
+// MapHosts extracts the hosts from a decoded JSON document using the host
+// path and field mapping of cfg. Entries without an alias or hostname are
+// skipped and counted in Snapshot.Skipped.
+func MapHosts(cfg models.HTTPSourceConfig, doc any) (*Snapshot, error) {
+	hostsPath, err := ParsePath(cfg.HostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: hostsPath: %w", cfg.SourceName(), err)
+	}
+	fieldPaths := make(map[string]Path, len(models.HTTPMappableFields))
+	for _, field := range models.HTTPMappableFields {
+		path, err := ParsePath(cfg.FieldPath(field))
+		if err != nil {
+			return nil, fmt.Errorf("%s: field %s: %w", cfg.SourceName(), field, err)
+		}
+		fieldPaths[field] = path
+	}
+
+	// The host path may select the array itself or its elements
+	entries := hostsPath.Find(doc)
+	if len(entries) == 1 {
+		if array, ok := entries[0].([]any); ok {
+			entries = array
+		}
+	}
+
+	snapshot := &Snapshot{Hosts: []models.Host{}}
+	for _, entry := range entries {
+		scalar := func(field string) string {
+			return fieldPaths[field].FirstString(entry)
+		}
+
+		host := models.Host{
+			Alias:        scalar("alias"),
+			Hostname:     scalar("hostname"),
+			User:         scalar("user"),
+			Port:         scalar("port"),
+			IdentityFile: scalar("identity_file"),
+			ProxyJump:    scalar("proxy_jump"),
+			DefaultPath:  scalar("default_path"),
+			Tags:         fieldPaths["tags"].Strings(entry),
+		}
+		if host.Alias == "" || host.Hostname == "" {
+			snapshot.Skipped++
+			continue
+		}
+		snapshot.Hosts = append(snapshot.Hosts, host)
+	}
+	return snapshot, nil
+}
+
+// scalarString converts a JSON string, number or boolean to a string
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// stringList flattens values, and arrays among them, into a list of strings
+func stringList(values []any) []string {
+	var list []string
+	for _, value := range values {
+		if array, ok := value.([]any); ok {
+			list = append(list, stringList(array)...)
+			continue
+		}
+		if s, ok := scalarString(value); ok && s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,218 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestFetchHostsAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  models.HTTPSourceConfig
+		want string // Authorization header
+	}{
+		{"none", models.HTTPSourceConfig{}, ""},
+		{"bearer", models.HTTPSourceConfig{Token: "s3cret"}, "Bearer s3cret"},
+		{"basic", models.HTTPSourceConfig{Username: "ops", Password: "pw"}, "Basic b3BzOnB3"},
+		{"token wins over basic", models.HTTPSourceConfig{Token: "s3cret", Username: "ops", Password: "pw"}, "Bearer s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				w.Write([]byte("[]"))
+			}))
+			defer server.Close()
+
+			tt.cfg.Name = "inv"
+			tt.cfg.URL = server.URL
+			if _, err := NewClient(tt.cfg).FetchHosts(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchHostsConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Wed, 14 Oct 2026 09:00:00 GMT"
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(`[{"alias": "web", "hostname": "10.0.0.1"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(models.HTTPSourceConfig{Name: "inv", URL: server.URL})
+	first, err := client.FetchHosts(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NotModified || first.ETag != etag || first.LastModified != lastModified || len(first.Hosts) != 1 {
+		t.Fatalf("first fetch = %+v", first)
+	}
+
+	tests := []struct {
+		name     string
+		previous Snapshot
+	}{
+		{"etag", Snapshot{ETag: etag, Hosts: first.Hosts}},
+		{"last modified", Snapshot{LastModified: lastModified, Hosts: first.Hosts}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second, err := client.FetchHosts(context.Background(), &tt.previous)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !second.NotModified {
+				t.Error("expected the list to be reported as not modified")
+			}
+			if !reflect.DeepEqual(second.Hosts, first.Hosts) {
+				t.Errorf("got hosts %+v, want the cached %+v", second.Hosts, first.Hosts)
+			}
+		})
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+}
+
+func TestFetchHostsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"server error", http.StatusInternalServerError, "boom", "status 500: boom"},
+		{"not modified without a cache", http.StatusNotModified, "", "status 304"},
+		{"invalid JSON", http.StatusOK, "<html>", "not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewClient(models.HTTPSourceConfig{Name: "inv", URL: server.URL}).FetchHosts(context.Background(), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMapHosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         models.HTTPSourceConfig
+		doc         string
+		want        []models.Host
+		wantSkipped int
+	}{
+		{
+			name: "default fields",
+			doc:  `[{"alias": "web", "hostname": "10.0.0.1", "user": "deploy", "port": 2222, "tags": ["prod", "web"]}]`,
+			want: []models.Host{{Alias: "web", Hostname: "10.0.0.1", User: "deploy", Port: "2222", Tags: []string{"prod", "web"}}},
+		},
+		{
+			name: "nested host path and field paths",
+			cfg: models.HTTPSourceConfig{
+				HostsPath: "$.data.servers",
+				Fields: map[string]string{
+					"alias":    "$.name",
+					"hostname": "$.network.ip",
+					"user":     "$.ssh['login']",
+					"tags":     "$.labels[*].value",
+				},
+			},
+			doc: `{"data": {"servers": [
+				{"name": "web", "network": {"ip": "10.0.0.1"}, "ssh": {"login": "ops"}, "labels": [{"value": "prod"}, {"value": 7}]}
+			]}}`,
+			want: []models.Host{{Alias: "web", Hostname: "10.0.0.1", User: "ops", Tags: []string{"prod", "7"}}},
+		},
+		{
+			name: "host path selecting elements",
+			cfg:  models.HTTPSourceConfig{HostsPath: "$.hosts[*]"},
+			doc:  `{"hosts": [{"alias": "a", "hostname": "h1"}, {"alias": "b", "hostname": "h2"}]}`,
+			want: []models.Host{{Alias: "a", Hostname: "h1"}, {Alias: "b", Hostname: "h2"}},
+		},
+		{
+			name: "missing fields are left empty",
+			doc:  `[{"alias": "web", "hostname": "10.0.0.1", "tags": null}]`,
+			want: []models.Host{{Alias: "web", Hostname: "10.0.0.1"}},
+		},
+		{
+			name: "tags as a single value",
+			doc:  `[{"alias": "web", "hostname": "10.0.0.1", "tags": "prod"}]`,
+			want: []models.Host{{Alias: "web", Hostname: "10.0.0.1", Tags: []string{"prod"}}},
+		},
+		{
+			name: "nested tag arrays are flattened",
+			doc:  `[{"alias": "web", "hostname": "10.0.0.1", "tags": [["prod", ""], ["web"]]}]`,
+			want: []models.Host{{Alias: "web", Hostname: "10.0.0.1", Tags: []string{"prod", "web"}}},
+		},
+		{
+			name:        "entries without alias or hostname are skipped",
+			doc:         `[{"alias": "web"}, {"hostname": "10.0.0.2"}, {"alias": "db", "hostname": "10.0.0.3"}, "junk"]`,
+			want:        []models.Host{{Alias: "db", Hostname: "10.0.0.3"}},
+			wantSkipped: 3,
+		},
+		{
+			name: "host path not found",
+			cfg:  models.HTTPSourceConfig{HostsPath: "$.missing"},
+			doc:  `{"hosts": []}`,
+			want: []models.Host{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := json.NewDecoder(strings.NewReader(tt.doc))
+			decoder.UseNumber()
+			var doc any
+			if err := decoder.Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, err := MapHosts(tt.cfg, doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(snapshot.Hosts, tt.want) {
+				t.Errorf("got hosts %+v, want %+v", snapshot.Hosts, tt.want)
+			}
+			if snapshot.Skipped != tt.wantSkipped {
+				t.Errorf("skipped %d, want %d", snapshot.Skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestMapHostsInvalidPath(t *testing.T) {
+	cfg := models.HTTPSourceConfig{Name: "inv", Fields: map[string]string{"hostname": "$.a[unclosed"}}
+	if _, err := MapHosts(cfg, []any{}); err == nil || !strings.Contains(err.Error(), "field hostname") {
+		t.Errorf("got error %v, want one naming the field", err)
+	}
+}
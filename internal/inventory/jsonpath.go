@@ -0,0 +1,154 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression. The supported subset covers what
+// host lists need: "$" for the root, ".name" and "['name']" for properties,
+// "[n]" for array elements and "[*]" or ".*" for all elements or values.
+type Path struct {
+	expr  string
+	steps []pathStep
+}
+
+type pathStepKind int
+
+const (
+	stepKey pathStepKind = iota
+	stepIndex
+	stepWildcard
+)
+
+// pathStep is one step of a path
+type pathStep struct {
+	kind  pathStepKind
+	key   string
+	index int
+}
+
+// ParsePath compiles a JSONPath expression. The leading "$" is optional, so
+// "name" and "$.name" are the same path.
+func ParsePath(expr string) (Path, error) {
+	p := Path{expr: expr}
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return Path{}, fmt.Errorf("invalid path %q: empty property name", expr)
+			case "*":
+				p.steps = append(p.steps, pathStep{kind: stepWildcard})
+			default:
+				p.steps = append(p.steps, pathStep{kind: stepKey, key: name})
+			}
+
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return Path{}, fmt.Errorf("invalid path %q: missing ]", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				p.steps = append(p.steps, pathStep{kind: stepWildcard})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				p.steps = append(p.steps, pathStep{kind: stepKey, key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return Path{}, fmt.Errorf("invalid path %q: bad index %q", expr, inner)
+				}
+				p.steps = append(p.steps, pathStep{kind: stepIndex, index: index})
+			}
+
+		default:
+			return Path{}, fmt.Errorf("invalid path %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// String returns the expression the path was parsed from
+func (p Path) String() string {
+	return p.expr
+}
+
+// Find returns the values the path selects in a decoded JSON document.
+// Missing properties and out of range indexes select nothing; negative
+// indexes count from the end of an array.
+func (p Path) Find(doc any) []any {
+	values := []any{doc}
+	for _, step := range p.steps {
+		var next []any
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]any:
+				switch step.kind {
+				case stepKey:
+					if child, ok := v[step.key]; ok {
+						next = append(next, child)
+					}
+				case stepWildcard:
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				}
+			case []any:
+				switch step.kind {
+				case stepIndex:
+					index := step.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				case stepWildcard:
+					next = append(next, v...)
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// FirstString returns the first value the path selects that is a string,
+// number or boolean, as a string, or "" if there is none
+func (p Path) FirstString(doc any) string {
+	for _, value := range p.Find(doc) {
+		if s, ok := scalarString(value); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// Strings returns the strings, numbers and booleans the path selects, with
+// arrays among them flattened
+func (p Path) Strings(doc any) []string {
+	return stringList(p.Find(doc))
+}
@@ -0,0 +1,429 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// ConfigFile is a lossless, line-based representation of an SSH config file.
+// Comments, blank lines, indentation, separators and line endings are kept
+// exactly as read, so serializing an unmodified file reproduces it byte for
+// byte and an edit to one host only rewrites the lines of that host's block.
+type ConfigFile struct {
+	Path  string
+	Lines []*ConfigLine
+}
+
+// ConfigLine is a single physical line of an SSH config file
+type ConfigLine struct {
+	Raw    string // Original text without the trailing newline (may end in \r)
+	Indent string // Leading whitespace
+	Key    string // Keyword as written; empty for blank and comment lines
+	Sep    string // Separator between keyword and value as written (" ", "=", " = ")
+	Value  string // Value as written
+	eol    string // "\r" for CRLF files, kept when the line is rewritten
+}
+
+// ParseConfigFile reads the SSH config file at path. A missing file yields an
+// empty ConfigFile so hosts can be added to it.
+func ParseConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return ParseConfigBytes(path, data), nil
+}
+
+// ParseConfigBytes parses SSH config content without touching the filesystem
+func ParseConfigBytes(path string, data []byte) *ConfigFile {
+	cf := &ConfigFile{Path: path}
+	if len(data) == 0 {
+		return cf
+	}
+	for _, raw := range strings.Split(string(data), "\n") {
+		cf.Lines = append(cf.Lines, parseConfigLine(raw))
+	}
+	return cf
+}
+
+// parseConfigLine splits a raw line into its parts
+func parseConfigLine(raw string) *ConfigLine {
+	line := &ConfigLine{Raw: raw}
+
+	text := raw
+	if strings.HasSuffix(text, "\r") {
+		line.eol = "\r"
+		text = strings.TrimSuffix(text, "\r")
+	}
+
+	trimmed := strings.TrimLeft(text, " \t")
+	line.Indent = text[:len(text)-len(trimmed)]
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return line
+	}
+
+	end := strings.IndexAny(trimmed, " \t=")
+	if end == -1 {
+		line.Key = trimmed
+		return line
+	}
+	line.Key = trimmed[:end]
+
+	rest := trimmed[end:]
+	value := strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(value, "=") {
+		value = strings.TrimLeft(value[1:], " \t")
+	}
+	line.Sep = rest[:len(rest)-len(value)]
+	line.Value = value
+	return line
+}
+
+// String serializes the file
+func (cf *ConfigFile) String() string {
+	raws := make([]string, len(cf.Lines))
+	for i, line := range cf.Lines {
+		raws[i] = line.Raw
+	}
+	return strings.Join(raws, "\n")
+}
+
+// WriteFile writes the file back to cf.Path, keeping the existing permissions.
+// Symlinked configs (e.g. from a dotfiles repo) are updated at their target.
+func (cf *ConfigFile) WriteFile() error {
+	path := cf.Path
+	mode := os.FileMode(0600)
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failed write cannot truncate the config
+	tmpPath := path + ".sshbuddy.tmp"
+	if err := os.WriteFile(tmpPath, []byte(cf.String()), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// keyword returns the lower-cased keyword of the line
+func (l *ConfigLine) keyword() string {
+	return strings.ToLower(l.Key)
+}
+
+// setValue replaces the line's value, keeping indentation, keyword and separator
+func (l *ConfigLine) setValue(value string) {
+	sep := l.Sep
+	if sep == "" {
+		sep = " "
+	}
+	l.Value = value
+	l.Sep = sep
+	l.Raw = l.Indent + l.Key + sep + value + l.eol
+}
+
+// isBlockStart reports whether the line starts a Host or Match block
+func (l *ConfigLine) isBlockStart() bool {
+	keyword := l.keyword()
+	return keyword == "host" || keyword == "match"
+}
+
+// FindHost returns the line range [start, end) of the first Host block that
+// lists alias as one of its patterns, or ok=false if there is none
+func (cf *ConfigFile) FindHost(alias string) (start, end int, ok bool) {
+	for i, line := range cf.Lines {
+		if line.keyword() != "host" {
+			continue
+		}
+		for _, pattern := range splitArgs(line.Value) {
+			if pattern == alias {
+				return i, cf.blockEnd(i), true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// blockEnd returns the index of the line after the block starting at start
+func (cf *ConfigFile) blockEnd(start int) int {
+	for i := start + 1; i < len(cf.Lines); i++ {
+		if cf.Lines[i].isBlockStart() {
+			return i
+		}
+	}
+	return len(cf.Lines)
+}
+
+// blockIndent returns the indentation used by directives in a block
+func (cf *ConfigFile) blockIndent(start, end int) string {
+	for i := start + 1; i < end; i++ {
+		if cf.Lines[i].Key != "" {
+			return cf.Lines[i].Indent
+		}
+	}
+	return "    "
+}
+
+// lastDirective returns the index of the last directive line in a block,
+// which is the Host line itself for an empty block
+func (cf *ConfigFile) lastDirective(start, end int) int {
+	last := start
+	for i := start + 1; i < end; i++ {
+		if cf.Lines[i].Key != "" {
+			last = i
+		}
+	}
+	return last
+}
+
+// isolateHost makes sure alias has a Host block of its own. If alias shares a
+// Host line with other patterns, it is removed from that line and a copy of
+// the block is inserted right after it for alias alone, so its effective
+// settings are unchanged. Returns the range of alias's block.
+func (cf *ConfigFile) isolateHost(alias string) (start, end int, err error) {
+	start, end, ok := cf.FindHost(alias)
+	if !ok {
+		return 0, 0, fmt.Errorf("host %q not found in %s", alias, cf.Path)
+	}
+
+	hostLine := cf.Lines[start]
+	patterns := splitArgs(hostLine.Value)
+	if len(patterns) == 1 {
+		return start, end, nil
+	}
+
+	var remaining []string
+	for _, pattern := range patterns {
+		if pattern != alias {
+			remaining = append(remaining, pattern)
+		}
+	}
+	hostLine.setValue(strings.Join(remaining, " "))
+
+	// Copy the block's directives (not its trailing comments) for alias
+	last := cf.lastDirective(start, end)
+	copied := []*ConfigLine{parseConfigLine(hostLine.Indent + hostLine.Key + " " + alias + hostLine.eol)}
+	for i := start + 1; i <= last; i++ {
+		copied = append(copied, parseConfigLine(cf.Lines[i].Raw))
+	}
+
+	insertAt := last + 1
+	cf.insertLines(insertAt, copied...)
+	return insertAt, insertAt + len(copied), nil
+}
+
+// insertLines inserts lines before index i
+func (cf *ConfigFile) insertLines(i int, lines ...*ConfigLine) {
+	cf.Lines = append(cf.Lines[:i], append(lines, cf.Lines[i:]...)...)
+}
+
+// removeLine removes the line at index i
+func (cf *ConfigFile) removeLine(i int) {
+	cf.Lines = append(cf.Lines[:i], cf.Lines[i+1:]...)
+}
+
+// hostValues returns the values of key in alias's own Host block, without
+// the ones it inherits from Host * and other matching blocks
+func (cf *ConfigFile) hostValues(alias, key string) []string {
+	start, end, ok := cf.FindHost(alias)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for i := start + 1; i < end; i++ {
+		if cf.Lines[i].keyword() == strings.ToLower(key) {
+			values = append(values, strings.TrimSpace(cf.Lines[i].Value))
+		}
+	}
+	return values
+}
+
+// withoutValues returns values minus one occurrence of each of remove
+func withoutValues(values, remove []string) []string {
+	pending := make(map[string]int)
+	for _, value := range remove {
+		pending[value]++
+	}
+	var kept []string
+	for _, value := range values {
+		if pending[value] > 0 {
+			pending[value]--
+			continue
+		}
+		kept = append(kept, value)
+	}
+	return kept
+}
+
+// SetHostValues sets every occurrence of key in alias's block to values, in
+// order. Existing lines are rewritten in place, surplus lines are removed and
+// missing ones are added after the block's last directive. An empty values
+// slice removes the keyword from the block.
+func (cf *ConfigFile) SetHostValues(alias, key string, values []string) error {
+	start, end, err := cf.isolateHost(alias)
+	if err != nil {
+		return err
+	}
+
+	var existing []int
+	for i := start + 1; i < end; i++ {
+		if cf.Lines[i].keyword() == strings.ToLower(key) {
+			existing = append(existing, i)
+		}
+	}
+
+	for n, i := range existing {
+		if n < len(values) {
+			cf.Lines[i].setValue(values[n])
+		}
+	}
+
+	// Remove surplus lines from the bottom so earlier indexes stay valid
+	for n := len(existing) - 1; n >= len(values); n-- {
+		cf.removeLine(existing[n])
+		end--
+	}
+
+	if len(values) > len(existing) {
+		indent := cf.blockIndent(start, end)
+		eol := cf.Lines[start].eol
+		var added []*ConfigLine
+		for _, value := range values[len(existing):] {
+			added = append(added, parseConfigLine(indent+key+" "+value+eol))
+		}
+		cf.insertLines(cf.lastDirective(start, end)+1, added...)
+	}
+
+	return nil
+}
+
+// SetHostValue sets a single-valued keyword in alias's block; an empty value
+// removes it
+func (cf *ConfigFile) SetHostValue(alias, key, value string) error {
+	if value == "" {
+		return cf.SetHostValues(alias, key, nil)
+	}
+	return cf.SetHostValues(alias, key, []string{value})
+}
+
+// RenameHost replaces alias with newAlias on its Host line
+func (cf *ConfigFile) RenameHost(alias, newAlias string) error {
+	start, _, err := cf.isolateHost(alias)
+	if err != nil {
+		return err
+	}
+	cf.Lines[start].setValue(newAlias)
+	return nil
+}
+
+// UpdateHostInConfig writes the differences between original and updated to
+// the Host block for original.Alias, in whichever file of configPath (or its
+// includes) defines it. Only changed keywords are touched, so inherited
+// settings and the rest of the file are left as they are. Tags, the default
+// path and the ping method have no ssh_config equivalent and are not written.
+func UpdateHostInConfig(configPath string, original, updated models.Host) error {
+	p, err := parseSSHConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	// The first file defining the alias is the one whose values take effect
+	var cf *ConfigFile
+	for _, file := range p.files {
+		candidate, err := ParseConfigFile(file)
+		if err != nil {
+			return err
+		}
+		if _, _, ok := candidate.FindHost(original.Alias); ok {
+			cf = candidate
+			break
+		}
+	}
+	if cf == nil {
+		return fmt.Errorf("host %q not found in %s", original.Alias, configPath)
+	}
+
+	alias := original.Alias
+	setIfChanged := func(key, before, after string) error {
+		if before == after {
+			return nil
+		}
+		return cf.SetHostValue(alias, key, after)
+	}
+	// Forwards accumulate over every block matching the host, so before and
+	// after include the ones inherited from Host * and wildcard blocks. Only
+	// the forwards the host's own block adds are written; writing inherited
+	// ones too would make ssh set them up twice.
+	setAllIfChanged := func(key string, before, after []string) error {
+		if strings.Join(before, "\n") == strings.Join(after, "\n") {
+			return nil
+		}
+		own := cf.hostValues(alias, key)
+		inherited := withoutValues(before, own)
+		values := withoutValues(after, inherited)
+		if strings.Join(own, "\n") == strings.Join(values, "\n") {
+			return nil
+		}
+		return cf.SetHostValues(alias, key, values)
+	}
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	steps := []func() error{
+		func() error { return setIfChanged("HostName", original.Hostname, updated.Hostname) },
+		func() error { return setIfChanged("User", original.User, updated.User) },
+		func() error { return setIfChanged("Port", original.Port, updated.Port) },
+		func() error { return setIfChanged("IdentityFile", original.IdentityFile, updated.IdentityFile) },
+		func() error { return setIfChanged("ProxyJump", original.ProxyJump, updated.ProxyJump) },
+		func() error { return setAllIfChanged("LocalForward", original.LocalForwards, updated.LocalForwards) },
+		func() error { return setAllIfChanged("RemoteForward", original.RemoteForwards, updated.RemoteForwards) },
+		func() error {
+			return setAllIfChanged("DynamicForward", original.DynamicForwards, updated.DynamicForwards)
+		},
+		func() error {
+			return setIfChanged("ForwardAgent", yesNo(original.ForwardAgent), yesNo(updated.ForwardAgent))
+		},
+		func() error {
+			return setIfChanged("ServerAliveInterval", original.ServerAliveInterval, updated.ServerAliveInterval)
+		},
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	// Extra options: rewrite changed keys and drop removed ones
+	for _, key := range updated.SortedOptionKeys() {
+		if err := setIfChanged(key, original.Options[key], updated.Options[key]); err != nil {
+			return err
+		}
+	}
+	for _, key := range original.SortedOptionKeys() {
+		if _, kept := updated.Options[key]; !kept {
+			if err := cf.SetHostValue(alias, key, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Rename last so the steps above can find the block by its old alias
+	if updated.Alias != original.Alias {
+		if err := cf.RenameHost(alias, updated.Alias); err != nil {
+			return err
+		}
+	}
+
+	return cf.WriteFile()
+}
@@ -0,0 +1,224 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes content to name in dir and returns its path
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readConfig returns the content of the file at path
+func readConfig(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// loadHost returns the host with alias from the SSH config at path
+func loadHost(t *testing.T, path, alias string) SSHConfigHost {
+	t.Helper()
+	hosts, err := ParseSSHConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range hosts {
+		if host.Host == alias {
+			return host
+		}
+	}
+	t.Fatalf("host %q not found in %s", alias, path)
+	return SSHConfigHost{}
+}
+
+func TestConfigFileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"single newline", "\n"},
+		{"no trailing newline", "Host web\n    HostName 10.0.0.1"},
+		{
+			"comments and blank lines",
+			"# Global settings\n\nHost *\n    ServerAliveInterval 60   # keepalive\n\n\n# Work\nHost web\n    HostName 10.0.0.1\n\n",
+		},
+		{
+			"mixed indentation and separators",
+			"Host web db\n\tHostName=10.0.0.1\n  User = admin\n        Port\t2222\nHostName   trailing   \n",
+		},
+		{
+			"CRLF line endings",
+			"Host web\r\n    HostName 10.0.0.1\r\n    User admin\r\n\r\n# done\r\n",
+		},
+		{
+			"Include and Match",
+			"Include ~/.ssh/config.d/*\nInclude \"conf d/extra\"\n\nMatch host *.internal exec \"true\"\n    User ops\nHost web\n    HostName 10.0.0.1\n",
+		},
+		{
+			"quoted values and unknown keywords",
+			"Host web\n    IdentityFile \"~/My Keys/id_ed25519\"\n    SomeFutureKeyword on\n    LocalForward 8080 localhost:80\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := ParseConfigBytes("config", []byte(tt.content))
+			if got := cf.String(); got != tt.content {
+				t.Errorf("round trip changed the file\n got: %q\nwant: %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestConfigFileWriteUntouched(t *testing.T) {
+	dir := t.TempDir()
+	content := "# keep me\r\nHost web\r\n\tHostName 10.0.0.1\r\n\r\n"
+	path := writeConfig(t, dir, "config", content)
+
+	cf, err := ParseConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cf.WriteFile(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readConfig(t, path); got != content {
+		t.Errorf("writing an untouched file changed it\n got: %q\nwant: %q", got, content)
+	}
+}
+
+func TestSetHostValueRewritesOnlyThatBlock(t *testing.T) {
+	before := "# Servers\n\nHost web\n    HostName 10.0.0.1\n    User admin # inline\n\nHost db\n\tHostName=10.0.0.2\n\tUser  root\n\n# trailing comment\n"
+
+	tests := []struct {
+		name  string
+		edit  func(cf *ConfigFile) error
+		after string
+	}{
+		{
+			"change a value",
+			func(cf *ConfigFile) error { return cf.SetHostValue("db", "User", "postgres") },
+			"# Servers\n\nHost web\n    HostName 10.0.0.1\n    User admin # inline\n\nHost db\n\tHostName=10.0.0.2\n\tUser  postgres\n\n# trailing comment\n",
+		},
+		{
+			"add a value",
+			func(cf *ConfigFile) error { return cf.SetHostValue("web", "Port", "2222") },
+			"# Servers\n\nHost web\n    HostName 10.0.0.1\n    User admin # inline\n    Port 2222\n\nHost db\n\tHostName=10.0.0.2\n\tUser  root\n\n# trailing comment\n",
+		},
+		{
+			"remove a value",
+			func(cf *ConfigFile) error { return cf.SetHostValue("db", "User", "") },
+			"# Servers\n\nHost web\n    HostName 10.0.0.1\n    User admin # inline\n\nHost db\n\tHostName=10.0.0.2\n\n# trailing comment\n",
+		},
+		{
+			"rename",
+			func(cf *ConfigFile) error { return cf.RenameHost("web", "www") },
+			"# Servers\n\nHost www\n    HostName 10.0.0.1\n    User admin # inline\n\nHost db\n\tHostName=10.0.0.2\n\tUser  root\n\n# trailing comment\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := ParseConfigBytes("config", []byte(before))
+			if err := tt.edit(cf); err != nil {
+				t.Fatal(err)
+			}
+			if got := cf.String(); got != tt.after {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.after)
+			}
+		})
+	}
+}
+
+func TestSetHostValueSplitsSharedHostLine(t *testing.T) {
+	cf := ParseConfigBytes("config", []byte("Host web db\n    User admin\n\nHost other\n"))
+	if err := cf.SetHostValue("db", "User", "root"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Host web\n    User admin\nHost db\n    User root\n\nHost other\n"
+	if got := cf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUpdateHostInConfigKeepsOtherBlocksAndIncludes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	included := "# Included hosts\r\nHost db\r\n    HostName 10.0.0.2\r\n"
+	includedPath := writeConfig(t, dir, "extra.conf", included)
+	path := writeConfig(t, dir, "config",
+		"Include "+includedPath+"\n\n# Web\nHost web\n    HostName 10.0.0.1\n    User admin\n\nHost *\n    ServerAliveInterval 60\n")
+
+	original := ConvertToHost(loadHost(t, path, "web"))
+	updated := original
+	updated.Port = "2222"
+	if err := UpdateHostInConfig(path, original, updated); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Include " + includedPath + "\n\n# Web\nHost web\n    HostName 10.0.0.1\n    User admin\n    Port 2222\n\nHost *\n    ServerAliveInterval 60\n"
+	if got := readConfig(t, path); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := readConfig(t, includedPath); got != included {
+		t.Errorf("included file changed:\n%q", got)
+	}
+}
+
+func TestUpdateHostInConfigDoesNotCopyInheritedForwards(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	path := writeConfig(t, dir, "config",
+		"Host web\n    HostName 10.0.0.1\n    LocalForward 8080 localhost:80\n\nHost *\n    LocalForward 9000 localhost:9000\n    DynamicForward 1080\n")
+
+	original := ConvertToHost(loadHost(t, path, "web"))
+	if len(original.LocalForwards) != 2 {
+		t.Fatalf("expected the inherited forward to be loaded, got %v", original.LocalForwards)
+	}
+
+	// Add a forward in the form, which lists the inherited ones too
+	updated := original
+	updated.LocalForwards = append(append([]string{}, original.LocalForwards...), "5432 db:5432")
+	if err := UpdateHostInConfig(path, original, updated); err != nil {
+		t.Fatal(err)
+	}
+	want := "Host web\n    HostName 10.0.0.1\n    LocalForward 8080 localhost:80\n    LocalForward 5432 db:5432\n\nHost *\n    LocalForward 9000 localhost:9000\n    DynamicForward 1080\n"
+	if got := readConfig(t, path); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Saving again without changes leaves the file alone
+	original = ConvertToHost(loadHost(t, path, "web"))
+	if err := UpdateHostInConfig(path, original, original); err != nil {
+		t.Fatal(err)
+	}
+	if got := readConfig(t, path); got != want {
+		t.Errorf("second save changed the file:\n%s", got)
+	}
+
+	// Removing the block's own forward keeps the inherited one out of the block
+	updated = original
+	updated.LocalForwards = []string{"9000 localhost:9000"}
+	updated.DynamicForwards = original.DynamicForwards
+	if err := UpdateHostInConfig(path, original, updated); err != nil {
+		t.Fatal(err)
+	}
+	want = "Host web\n    HostName 10.0.0.1\n\nHost *\n    LocalForward 9000 localhost:9000\n    DynamicForward 1080\n"
+	if got := readConfig(t, path); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
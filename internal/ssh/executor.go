@@ -10,15 +10,30 @@ import (
 
 // ExecuteSSH executes SSH connection in the foreground
 func ExecuteSSH(host models.Host) error {
-	port := host.Port
-	if port == "" {
-		port = "22"
+	if host.IsDocker() {
+		return executeDocker(host)
 	}
 
+	cmd := exec.Command("ssh", sshArgs(host)...)
+
+	// Connect to current terminal for interactive SSH session
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Run SSH in foreground and wait for it to complete
+	return cmd.Run()
+}
+
+// sshArgs returns the ssh arguments that connect to host. Settings the host
+// leaves unset, such as its port, are left to ssh and its config.
+func sshArgs(host models.Host) []string {
 	var args []string
 
-	// Add port
-	args = append(args, "-p", port)
+	// Add port if specified
+	if host.Port != "" {
+		args = append(args, "-p", host.Port)
+	}
 
 	// Add identity file if specified
 	if host.IdentityFile != "" {
@@ -30,11 +45,36 @@ func ExecuteSSH(host models.Host) error {
 		args = append(args, "-J", host.ProxyJump)
 	}
 
+	// Add port forwards (stored in ssh_config syntax, where the listen and
+	// target parts are separated by whitespace instead of a colon)
+	for _, spec := range host.LocalForwards {
+		args = append(args, "-L", forwardArg(spec))
+	}
+	for _, spec := range host.RemoteForwards {
+		args = append(args, "-R", forwardArg(spec))
+	}
+	for _, spec := range host.DynamicForwards {
+		args = append(args, "-D", forwardArg(spec))
+	}
+
+	// Add agent forwarding and keepalives
+	if host.ForwardAgent {
+		args = append(args, "-A")
+	}
+	if host.ServerAliveInterval != "" {
+		args = append(args, "-o", "ServerAliveInterval="+host.ServerAliveInterval)
+	}
+
+	// Pass through any other ssh_config options
+	for _, key := range host.SortedOptionKeys() {
+		args = append(args, "-o", key+"="+host.Options[key])
+	}
+
 	// If a default path is specified, use -t to allocate a pseudo-terminal
 	// and execute a command to cd into the directory
 	if host.DefaultPath != "" {
 		args = append(args, "-t")
-		args = append(args, fmt.Sprintf("%s@%s", host.User, host.Hostname))
+		args = append(args, host.Destination())
 
 		// Escape the path for use in double quotes to prevent command injection
 		// but allow tilde and variable expansion
@@ -45,20 +85,42 @@ func ExecuteSSH(host models.Host) error {
 		args = append(args, fmt.Sprintf("cd \"%s\" && exec $SHELL -l", escapedPath))
 	} else {
 		// Standard connection without default path
-		args = append(args, fmt.Sprintf("%s@%s", host.User, host.Hostname))
+		args = append(args, host.Destination())
 	}
+	return args
+}
 
-	cmd := exec.Command("ssh", args...)
+// executeDocker opens a shell in the host's container with docker exec,
+// as the host's user and in its default path when they are set
+func executeDocker(host models.Host) error {
+	cmd := exec.Command("docker", dockerExecArgs(host)...)
 
-	// Connect to current terminal for interactive SSH session
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	// Run SSH in foreground and wait for it to complete
 	return cmd.Run()
 }
 
+// dockerExecArgs returns the docker arguments that open a shell in the
+// container of a docker host
+func dockerExecArgs(host models.Host) []string {
+	args := []string{"exec", "-it"}
+	if host.User != "" {
+		args = append(args, "-u", host.User)
+	}
+	if host.DefaultPath != "" {
+		args = append(args, "-w", host.DefaultPath)
+	}
+	return append(args, host.Hostname, "sh")
+}
+
+// forwardArg converts a forward spec from ssh_config syntax
+// ("8080 localhost:80") to command line syntax ("8080:localhost:80")
+func forwardArg(spec string) string {
+	return strings.Join(strings.Fields(spec), ":")
+}
+
 // escapeForDoubleQuotes escapes characters that need escaping within double quotes
 // and converts ~ to $HOME for proper expansion (since ~ doesn't expand in double quotes)
 func escapeForDoubleQuotes(path string) string {
@@ -0,0 +1,55 @@
+package ssh
+
+import (
+	"reflect"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestSSHArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		host models.Host
+		want []string
+	}{
+		{
+			name: "port left to ssh",
+			host: models.Host{Alias: "web", Hostname: "10.0.0.1"},
+			want: []string{"10.0.0.1"},
+		},
+		{
+			name: "port and user",
+			host: models.Host{Alias: "web", Hostname: "10.0.0.1", User: "admin", Port: "2222"},
+			want: []string{"-p", "2222", "admin@10.0.0.1"},
+		},
+		{
+			name: "port 22 set explicitly",
+			host: models.Host{Alias: "web", Hostname: "10.0.0.1", Port: "22"},
+			want: []string{"-p", "22", "10.0.0.1"},
+		},
+		{
+			name: "everything",
+			host: models.Host{
+				Alias: "web", Hostname: "10.0.0.1", User: "admin",
+				IdentityFile: "~/.ssh/web", ProxyJump: "bastion",
+				LocalForwards: []string{"8080 localhost:80"}, DynamicForwards: []string{"1080"},
+				ForwardAgent: true, ServerAliveInterval: "30",
+				Options:     map[string]string{"StrictHostKeyChecking": "no", "Compression": "yes"},
+				DefaultPath: "~/app",
+			},
+			want: []string{
+				"-i", "~/.ssh/web", "-J", "bastion", "-L", "8080:localhost:80", "-D", "1080", "-A",
+				"-o", "ServerAliveInterval=30", "-o", "Compression=yes", "-o", "StrictHostKeyChecking=no",
+				"-t", "admin@10.0.0.1", `cd "$HOME/app" && exec $SHELL -l`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sshArgs(tt.host); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// PingHistorySize is the number of probe results kept per host
+const PingHistorySize = 30
+
+// PingSample is a single recorded probe result
+type PingSample struct {
+	Time      time.Time `json:"time"`
+	OK        bool      `json:"ok"`
+	LatencyMs float64   `json:"latencyMs,omitempty"`
+}
+
+// PingHistory keeps a rolling window of probe results per host alias
+type PingHistory map[string][]PingSample
+
+// sparkBlocks are the sparkline levels, lowest latency first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkFailed marks a failed probe in a sparkline
+const sparkFailed = '×'
+
+// Record appends a probe result for alias, dropping the oldest sample once
+// the window is full
+func (h PingHistory) Record(alias string, ok bool, latency time.Duration) {
+	sample := PingSample{
+		Time: time.Now(),
+		OK:   ok,
+	}
+	if ok {
+		sample.LatencyMs = float64(latency) / float64(time.Millisecond)
+	}
+
+	samples := append(h[alias], sample)
+	if len(samples) > PingHistorySize {
+		samples = samples[len(samples)-PingHistorySize:]
+	}
+	h[alias] = samples
+}
+
+// Last returns up to n of the most recent samples for alias, oldest first
+func (h PingHistory) Last(alias string, n int) []PingSample {
+	samples := h[alias]
+	if n > 0 && len(samples) > n {
+		samples = samples[len(samples)-n:]
+	}
+	return samples
+}
+
+// Loss returns the percentage of failed probes among samples
+func Loss(samples []PingSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	failed := 0
+	for _, sample := range samples {
+		if !sample.OK {
+			failed++
+		}
+	}
+	return float64(failed) * 100 / float64(len(samples))
+}
+
+// LatencyStats returns the minimum, average and maximum latency of the
+// successful probes among samples
+func LatencyStats(samples []PingSample) (minMs, avgMs, maxMs float64) {
+	count := 0
+	minMs = math.MaxFloat64
+	for _, sample := range samples {
+		if !sample.OK {
+			continue
+		}
+		count++
+		avgMs += sample.LatencyMs
+		minMs = math.Min(minMs, sample.LatencyMs)
+		maxMs = math.Max(maxMs, sample.LatencyMs)
+	}
+	if count == 0 {
+		return 0, 0, 0
+	}
+	return minMs, avgMs / float64(count), maxMs
+}
+
+// Sparkline renders samples as one block character each, scaled between the
+// lowest and highest latency in the window. Failed probes are shown as ×.
+func Sparkline(samples []PingSample) string {
+	minMs, _, maxMs := LatencyStats(samples)
+
+	var sb strings.Builder
+	for _, sample := range samples {
+		if !sample.OK {
+			sb.WriteRune(sparkFailed)
+			continue
+		}
+		level := 0
+		if maxMs > minMs {
+			level = int((sample.LatencyMs - minMs) / (maxMs - minMs) * float64(len(sparkBlocks)-1))
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String()
+}
@@ -0,0 +1,273 @@
+package ssh
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultKnownHostsPath is the known_hosts file read when none is configured
+const DefaultKnownHostsPath = "~/.ssh/known_hosts"
+
+// KnownHost is a host SSH has connected to before
+type KnownHost struct {
+	Hostname string
+	Port     string // "" for the default port
+	User     string // Only known for hosts found in the shell history
+	// Others are the other names of the host on its known_hosts line, such
+	// as the IP address CheckHostIP adds; they only serve to match the host
+	Others []KnownHost
+}
+
+// pattern returns how the host is written in known_hosts: the host name, or
+// "[host]:port" for other ports than 22
+func (h KnownHost) pattern() string {
+	if h.Port == "" || h.Port == "22" {
+		return h.Hostname
+	}
+	return "[" + h.Hostname + "]:" + h.Port
+}
+
+// ParseKnownHosts reads the hosts of a known_hosts file, in the order they
+// first appear. A line is one host, named by its first name that isn't an IP
+// address; its other names end up in Others. Hashed names (HashKnownHosts)
+// can't be read back; they only count when one of candidates matches their
+// hash. A line sharing a name with an earlier one is the same host and
+// skipped. Wildcard patterns, negations and @cert-authority or @revoked
+// lines are skipped too. A leading ~/ in path is expanded, and a missing file
+// has no hosts.
+func ParseKnownHosts(path string, candidates []KnownHost) ([]KnownHost, error) {
+	if strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(homeDir, path[2:])
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var hosts []KnownHost
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // Keys of some types are long
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "@") {
+			continue // @cert-authority and @revoked lines don't name hosts
+		}
+
+		var names []KnownHost
+		for _, name := range strings.Split(fields[0], ",") {
+			if strings.HasPrefix(name, "|1|") {
+				for _, candidate := range candidates {
+					if matchHashedHost(name, candidate.pattern()) {
+						names = append(names, candidate)
+						break
+					}
+				}
+				continue
+			}
+			if strings.ContainsAny(name, "*?!") {
+				continue
+			}
+			if host, ok := parseKnownHostName(name); ok {
+				names = append(names, host)
+			}
+		}
+
+		host, ok := lineHost(names)
+		if !ok {
+			continue
+		}
+		duplicate := false
+		for _, name := range names {
+			duplicate = duplicate || seen[name.pattern()]
+			seen[name.pattern()] = true
+		}
+		if !duplicate {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, scanner.Err()
+}
+
+// lineHost makes the names of a known_hosts line into one host, named by the
+// first name that isn't an IP address
+func lineHost(names []KnownHost) (KnownHost, bool) {
+	if len(names) == 0 {
+		return KnownHost{}, false
+	}
+	primary := 0
+	for i, name := range names {
+		if net.ParseIP(name.Hostname) == nil {
+			primary = i
+			break
+		}
+	}
+
+	host := names[primary]
+	for i, name := range names {
+		if i == primary {
+			continue
+		}
+		host.Others = append(host.Others, name)
+		if host.User == "" && name.User != "" && name.Port == host.Port {
+			host.User = name.User // From the shell history, under another name
+		}
+	}
+	return host, true
+}
+
+// parseKnownHostName parses a host name or "[host]:port"
+func parseKnownHostName(name string) (KnownHost, bool) {
+	if strings.HasPrefix(name, "[") {
+		end := strings.Index(name, "]:")
+		if end == -1 {
+			return KnownHost{}, false
+		}
+		host := KnownHost{Hostname: name[1:end], Port: name[end+2:]}
+		if host.Port == "22" {
+			host.Port = ""
+		}
+		return host, host.Hostname != ""
+	}
+	return KnownHost{Hostname: name}, name != ""
+}
+
+// matchHashedHost reports whether a hashed known_hosts name, "|1|salt|hash",
+// is the hash of pattern
+func matchHashedHost(hashed, pattern string) bool {
+	parts := strings.Split(hashed, "|")
+	if len(parts) != 4 {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(pattern))
+	return hmac.Equal(mac.Sum(nil), hash)
+}
+
+// shellHistoryFiles are the history files searched for ssh commands,
+// relative to the home directory
+var shellHistoryFiles = []string{
+	".bash_history",
+	".zsh_history",
+	".local/share/fish/fish_history",
+}
+
+// ShellHistoryHosts returns the targets of the ssh commands in the shell
+// history of bash, zsh and fish, with the user and port they were given
+func ShellHistoryHosts() []KnownHost {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var hosts []KnownHost
+	seen := make(map[string]bool)
+	for _, name := range shellHistoryFiles {
+		data, err := os.ReadFile(filepath.Join(homeDir, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			// zsh writes ": <time>:<duration>;<command>", fish "- cmd: <command>"
+			if strings.HasPrefix(line, ": ") {
+				if _, command, ok := strings.Cut(line, ";"); ok {
+					line = command
+				}
+			}
+			line = strings.TrimPrefix(line, "- cmd: ")
+
+			if host, ok := parseSSHCommand(line); ok && !seen[host.User+"@"+host.pattern()] {
+				seen[host.User+"@"+host.pattern()] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
+
+// sshOptionsWithValue are the ssh options that take an argument
+const sshOptionsWithValue = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// parseSSHCommand returns the target of an "ssh [options] [user@]host"
+// command line, or of "ssh ssh://[user@]host[:port]"
+func parseSSHCommand(line string) (KnownHost, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "ssh" {
+		return KnownHost{}, false
+	}
+
+	var host KnownHost
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "-") || len(field) < 2 {
+			target := field
+			if strings.HasPrefix(target, "ssh://") {
+				u, err := url.Parse(target)
+				if err != nil {
+					return KnownHost{}, false
+				}
+				host.Hostname = u.Hostname()
+				if u.User != nil {
+					host.User = u.User.Username()
+				}
+				if u.Port() != "" {
+					host.Port = u.Port()
+				}
+			} else {
+				if user, hostname, ok := strings.Cut(target, "@"); ok {
+					host.User, target = user, hostname
+				}
+				host.Hostname = target
+			}
+			if host.Port == "22" {
+				host.Port = ""
+			}
+			return host, host.Hostname != "" && !strings.ContainsAny(host.Hostname, "$`'\"")
+		}
+
+		option := field[1]
+		if !strings.ContainsRune(sshOptionsWithValue, rune(option)) {
+			continue // Flags such as -v or -A, possibly combined
+		}
+		value := field[2:]
+		if value == "" && i+1 < len(fields) {
+			i++
+			value = fields[i]
+		}
+		switch option {
+		case 'p':
+			host.Port = value
+		case 'l':
+			host.User = value
+		}
+	}
+	return KnownHost{}, false
+}
@@ -0,0 +1,115 @@
+package ssh
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+// hashKnownHost hashes pattern like HashKnownHosts does
+func hashKnownHost(salt, pattern string) string {
+	mac := hmac.New(sha1.New, []byte(salt))
+	mac.Write([]byte(pattern))
+	return "|1|" + base64.StdEncoding.EncodeToString([]byte(salt)) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseKnownHosts(t *testing.T) {
+	const key = " ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"
+	hashedName := hashKnownHost("salt-one-1234567890", "db.example.com")
+	hashedIP := hashKnownHost("salt-two-1234567890", "198.51.100.7")
+
+	tests := []struct {
+		name       string
+		content    string
+		candidates []KnownHost
+		want       []KnownHost
+	}{
+		{
+			"name and address are one host",
+			"web.example.com,203.0.113.5" + key,
+			nil,
+			[]KnownHost{{Hostname: "web.example.com", Others: []KnownHost{{Hostname: "203.0.113.5"}}}},
+		},
+		{
+			"address first",
+			"203.0.113.5,web.example.com" + key,
+			nil,
+			[]KnownHost{{Hostname: "web.example.com", Others: []KnownHost{{Hostname: "203.0.113.5"}}}},
+		},
+		{
+			"only an address",
+			"203.0.113.9" + key,
+			nil,
+			[]KnownHost{{Hostname: "203.0.113.9"}},
+		},
+		{
+			"other port",
+			"[git.example.com]:2222,[203.0.113.6]:2222" + key + "[plain.example.com]:22" + key,
+			nil,
+			[]KnownHost{
+				{Hostname: "git.example.com", Port: "2222", Others: []KnownHost{{Hostname: "203.0.113.6", Port: "2222"}}},
+				{Hostname: "plain.example.com"},
+			},
+		},
+		{
+			"later line for a known name",
+			"web.example.com,203.0.113.5" + key + "203.0.113.5" + key + "web.example.com" + key,
+			nil,
+			[]KnownHost{{Hostname: "web.example.com", Others: []KnownHost{{Hostname: "203.0.113.5"}}}},
+		},
+		{
+			"hashed line matching candidates",
+			hashedName + "," + hashedIP + key,
+			[]KnownHost{{Hostname: "198.51.100.7"}, {Hostname: "db.example.com", User: "postgres"}, {Hostname: "other"}},
+			[]KnownHost{{Hostname: "db.example.com", User: "postgres", Others: []KnownHost{{Hostname: "198.51.100.7"}}}},
+		},
+		{
+			"hashed line without candidates",
+			hashedName + key,
+			nil,
+			nil,
+		},
+		{
+			"skipped lines",
+			"# comment\n*.example.com" + key + "@cert-authority *.example.com" + key + "!bad.example.com" + key + "short line\n",
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), "known_hosts", tt.content)
+			got, err := ParseKnownHosts(path, tt.candidates)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSSHCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		want KnownHost
+		ok   bool
+	}{
+		{"ssh web", KnownHost{Hostname: "web"}, true},
+		{"ssh -p 2222 admin@web", KnownHost{Hostname: "web", User: "admin", Port: "2222"}, true},
+		{"ssh -A -l ops -i ~/.ssh/key web", KnownHost{Hostname: "web", User: "ops"}, true},
+		{"ssh ssh://deploy@web:22", KnownHost{Hostname: "web", User: "deploy"}, true},
+		{"ssh $HOST", KnownHost{}, false},
+		{"ssh-keygen -R web", KnownHost{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSSHCommand(tt.line)
+		if ok != tt.ok || (ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("parseSSHCommand(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
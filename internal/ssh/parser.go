@@ -2,131 +2,417 @@ package ssh
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"sshbuddy/pkg/models"
+	"strings"
 )
 
 // SSHConfigHost represents a host entry from SSH config
 type SSHConfigHost struct {
-	Host             string
-	HostName         string
-	User             string
-	Port             string
-	IdentityFile     string
-	ProxyJump        string
-	ForwardAgent     string
-	LocalForward     string
-	RemoteForward    string
-	DynamicForward   string
+	Host                string
+	HostName            string
+	User                string
+	Port                string
+	IdentityFile        string
+	ProxyJump           string
+	ForwardAgent        string
+	LocalForwards       []string
+	RemoteForwards      []string
+	DynamicForwards     []string
 	ServerAliveInterval string
+	Options             map[string]string // Every other recognised directive, keyed by canonical keyword
+}
+
+// maxIncludeDepth mirrors OpenSSH's limit on nested Include directives
+const maxIncludeDepth = 16
+
+// sshConfigBlock is a Host (or Match) section in file order. Directives that
+// appear before the first Host line belong to an implicit "Host *" block.
+type sshConfigBlock struct {
+	patterns   []string // Host patterns; nil for Match blocks, which are never applied
+	directives []sshConfigDirective
+}
+
+// sshConfigDirective is a single keyword/value line inside a block
+type sshConfigDirective struct {
+	key   string // lower-cased keyword
+	value string
+}
+
+// sshConfigParser accumulates blocks across the main config file and any
+// files pulled in through Include directives
+type sshConfigParser struct {
+	homeDir string
+	blocks  []sshConfigBlock
+	files   []string        // every file read, in order
+	current int             // index of the block receiving directives
+	active  map[string]bool // files on the current include stack, for cycle detection
+}
+
+// ParseSSHConfig reads and parses the SSH config file at configPath, following
+// Include directives. A leading ~/ in configPath is expanded.
+//
+// Every concrete alias on a Host line becomes its own entry; wildcard and
+// negated patterns are not connectable, but their settings are inherited by
+// the aliases they match, with the first value for each keyword winning as
+// in OpenSSH.
+func ParseSSHConfig(configPath string) ([]SSHConfigHost, error) {
+	p, err := parseSSHConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.resolveHosts(), nil
+}
+
+// ParseSSHConfigData parses SSH config text that doesn't come from a file,
+// such as the output of `vagrant ssh-config`. Relative Include paths are
+// resolved against ~/.ssh.
+func ParseSSHConfigData(data []byte) ([]SSHConfigHost, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &sshConfigParser{
+		homeDir: homeDir,
+		blocks:  []sshConfigBlock{{patterns: []string{"*"}}},
+		active:  make(map[string]bool),
+	}
+	if err := p.parseLines(bytes.NewReader(data), 0); err != nil {
+		return nil, err
+	}
+	return p.resolveHosts(), nil
 }
 
-// ParseSSHConfig reads and parses the SSH config file
-func ParseSSHConfig() ([]SSHConfigHost, error) {
+// parseSSHConfig reads configPath and its includes into blocks. A missing
+// config file yields a parser with no hosts.
+func parseSSHConfig(configPath string) (*sshConfigParser, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
 
-	configPath := filepath.Join(homeDir, ".ssh", "config")
-	
+	p := &sshConfigParser{
+		homeDir: homeDir,
+		blocks:  []sshConfigBlock{{patterns: []string{"*"}}},
+		active:  make(map[string]bool),
+	}
+
+	configPath = expandHome(configPath, homeDir)
+
 	// Check if config file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return []SSHConfigHost{}, nil
+		return p, nil
 	}
 
-	file, err := os.Open(configPath)
-	if err != nil {
+	if err := p.parseFile(configPath, 0); err != nil {
 		return nil, err
 	}
+	return p, nil
+}
+
+// parseFile parses a single config file. Host blocks may span file boundaries:
+// directives in an included file apply to the enclosing Host until the
+// included file starts a Host of its own, just like OpenSSH.
+func (p *sshConfigParser) parseFile(path string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("ssh config: too many nested includes at %s", path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if p.active[absPath] {
+		// Include cycle - skip the file instead of recursing forever
+		return nil
+	}
+	p.active[absPath] = true
+	defer delete(p.active, absPath)
+	p.files = append(p.files, absPath)
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
-	var hosts []SSHConfigHost
-	var currentHost *SSHConfigHost
+	return p.parseLines(file, depth)
+}
 
-	scanner := bufio.NewScanner(file)
+// parseLines parses config lines into blocks, following Include directives
+func (p *sshConfigParser) parseLines(r io.Reader, depth int) error {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		key, value, ok := parseDirective(scanner.Text())
+		if !ok {
 			continue
 		}
 
-		// Split into key and value
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
-		}
-
-		key := strings.ToLower(parts[0])
-		value := strings.Join(parts[1:], " ")
-
 		switch key {
 		case "host":
-			// Save previous host if exists
-			if currentHost != nil && currentHost.Host != "*" {
-				hosts = append(hosts, *currentHost)
+			p.startBlock(splitArgs(value))
+		case "match":
+			p.startBlock(nil)
+		case "include":
+			enclosing := p.current
+			if err := p.include(value, depth); err != nil {
+				return err
 			}
-			// Start new host
-			currentHost = &SSHConfigHost{
-				Host: value,
+			// Directives after the Include belong to the enclosing block again
+			if p.current != enclosing {
+				p.startBlock(p.blocks[enclosing].patterns)
 			}
-		case "hostname":
-			if currentHost != nil {
-				currentHost.HostName = value
+		default:
+			block := &p.blocks[p.current]
+			block.directives = append(block.directives, sshConfigDirective{key: key, value: value})
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseDirective splits a config line into a lower-cased keyword and its value.
+// Both "Key value" and "Key=value" forms are accepted.
+func parseDirective(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+
+	// Skip empty lines and comments
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end == -1 {
+		return "", "", false
+	}
+
+	key := strings.ToLower(line[:end])
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	if value == "" {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// startBlock begins a new block; nil patterns mark a block that never applies
+func (p *sshConfigParser) startBlock(patterns []string) {
+	p.blocks = append(p.blocks, sshConfigBlock{patterns: patterns})
+	p.current = len(p.blocks) - 1
+}
+
+// include expands the arguments of an Include directive and parses every
+// matching file in lexical order. Relative paths are resolved against ~/.ssh.
+func (p *sshConfigParser) include(value string, depth int) error {
+	for _, pattern := range splitArgs(value) {
+		pattern = expandHome(pattern, p.homeDir)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(p.homeDir, ".ssh", pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("ssh config: bad Include pattern %q: %w", pattern, err)
+		}
+
+		// Missing include files are not an error in OpenSSH
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
 			}
-		case "user":
-			if currentHost != nil {
-				currentHost.User = value
+			if err := p.parseFile(match, depth+1); err != nil {
+				return err
 			}
-		case "port":
-			if currentHost != nil {
-				currentHost.Port = value
+		}
+	}
+	return nil
+}
+
+// resolveHosts builds one entry per concrete alias, in order of first
+// appearance, with the effective settings of every block that matches it
+func (p *sshConfigParser) resolveHosts() []SSHConfigHost {
+	var hosts []SSHConfigHost
+	seen := make(map[string]bool)
+
+	for _, block := range p.blocks {
+		for _, pattern := range block.patterns {
+			if isHostPattern(pattern) || seen[pattern] {
+				continue
 			}
-		case "identityfile":
-			if currentHost != nil {
-				// Expand ~ to home directory
-				if strings.HasPrefix(value, "~/") {
-					value = filepath.Join(homeDir, value[2:])
+			seen[pattern] = true
+
+			host := SSHConfigHost{Host: pattern}
+			for _, candidate := range p.blocks {
+				if !matchesHost(candidate.patterns, pattern) {
+					continue
+				}
+				for _, d := range candidate.directives {
+					p.apply(&host, d.key, d.value)
 				}
-				currentHost.IdentityFile = value
-			}
-		case "proxyjump":
-			if currentHost != nil {
-				currentHost.ProxyJump = value
-			}
-		case "forwardagent":
-			if currentHost != nil {
-				currentHost.ForwardAgent = value
-			}
-		case "localforward":
-			if currentHost != nil {
-				currentHost.LocalForward = value
-			}
-		case "remoteforward":
-			if currentHost != nil {
-				currentHost.RemoteForward = value
-			}
-		case "dynamicforward":
-			if currentHost != nil {
-				currentHost.DynamicForward = value
 			}
-		case "serveraliveinterval":
-			if currentHost != nil {
-				currentHost.ServerAliveInterval = value
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// apply stores a directive on host unless an earlier block already set it.
+// Forwards accumulate across all matching blocks, as in OpenSSH.
+func (p *sshConfigParser) apply(host *SSHConfigHost, key, value string) {
+	setFirst := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	switch key {
+	case "hostname":
+		setFirst(&host.HostName, value)
+	case "user":
+		setFirst(&host.User, value)
+	case "port":
+		setFirst(&host.Port, value)
+	case "identityfile":
+		// Expand ~ to home directory
+		setFirst(&host.IdentityFile, expandHome(value, p.homeDir))
+	case "proxyjump":
+		setFirst(&host.ProxyJump, value)
+	case "forwardagent":
+		setFirst(&host.ForwardAgent, value)
+	case "localforward":
+		host.LocalForwards = append(host.LocalForwards, value)
+	case "remoteforward":
+		host.RemoteForwards = append(host.RemoteForwards, value)
+	case "dynamicforward":
+		host.DynamicForwards = append(host.DynamicForwards, value)
+	case "serveraliveinterval":
+		setFirst(&host.ServerAliveInterval, value)
+	default:
+		// Keep any other known keyword so it is passed through on connect
+		keyword, ok := models.CanonicalSSHOption(key)
+		if !ok {
+			return
+		}
+		if host.Options == nil {
+			host.Options = make(map[string]string)
+		}
+		if _, exists := host.Options[keyword]; !exists {
+			host.Options[keyword] = value
+		}
+	}
+}
+
+// definesHost reports whether a block other than Host * applies to alias
+func (p *sshConfigParser) definesHost(alias string) bool {
+	for _, block := range p.blocks {
+		if len(block.patterns) == 1 && block.patterns[0] == "*" {
+			continue
+		}
+		if matchesHost(block.patterns, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHostPattern reports whether a Host pattern is a wildcard or negation
+// rather than a concrete, connectable alias
+func isHostPattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "*?")
+}
+
+// matchesHost reports whether alias matches a block's Host patterns. As in
+// OpenSSH, a matching negated pattern excludes the alias outright.
+func matchesHost(patterns []string, alias string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if negated := strings.TrimPrefix(pattern, "!"); negated != pattern {
+			if matchPattern(negated, alias) {
+				return false
 			}
+			continue
+		}
+		if matchPattern(pattern, alias) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchPattern matches s against an OpenSSH pattern where '*' matches any
+// sequence and '?' any single character, ignoring case
+func matchPattern(pattern, s string) bool {
+	pattern = strings.ToLower(pattern)
+	s = strings.ToLower(s)
+
+	// Iterative wildcard matching with backtracking to the last '*'
+	pi, si := 0, 0
+	star, mark := -1, 0
+	for si < len(s) {
+		switch {
+		case pi < len(pattern) && (pattern[pi] == '?' || pattern[pi] == s[si]):
+			pi++
+			si++
+		case pi < len(pattern) && pattern[pi] == '*':
+			star = pi
+			mark = si
+			pi++
+		case star != -1:
+			pi = star + 1
+			mark++
+			si = mark
+		default:
+			return false
 		}
 	}
+	for pi < len(pattern) && pattern[pi] == '*' {
+		pi++
+	}
+	return pi == len(pattern)
+}
 
-	// Add the last host
-	if currentHost != nil && currentHost.Host != "*" {
-		hosts = append(hosts, *currentHost)
+// splitArgs splits a directive value into arguments, honouring double quotes
+func splitArgs(value string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes := false
+	hasArg := false
+
+	for _, r := range value {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
 	}
+	return args
+}
 
-	return hosts, scanner.Err()
+// expandHome expands a leading ~/ to the user's home directory
+func expandHome(path, homeDir string) string {
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir, path[2:])
+	}
+	return path
 }
 
 // ConvertToHost converts an SSHConfigHost to a models.Host
@@ -136,49 +422,43 @@ func ConvertToHost(sshHost SSHConfigHost) models.Host {
 		hostname = sshHost.Host
 	}
 
-	port := sshHost.Port
-	if port == "" {
-		port = "22"
-	}
-
-	user := sshHost.User
-	if user == "" {
-		// Try to get current user
-		if currentUser := os.Getenv("USER"); currentUser != "" {
-			user = currentUser
-		} else {
-			user = "root"
-		}
-	}
-
 	// Build tags based on SSH config properties
 	var tags []string
 	tags = append(tags, "ssh-config")
-	
+
 	if sshHost.IdentityFile != "" {
 		tags = append(tags, "key-auth")
 	}
 	if sshHost.ProxyJump != "" {
 		tags = append(tags, "proxy")
 	}
-	if sshHost.LocalForward != "" || sshHost.RemoteForward != "" || sshHost.DynamicForward != "" {
+	if len(sshHost.LocalForwards) > 0 || len(sshHost.RemoteForwards) > 0 || len(sshHost.DynamicForwards) > 0 {
 		tags = append(tags, "forwarding")
 	}
 
+	// ForwardAgent may also name an agent socket, which still enables forwarding
+	forwardAgent := sshHost.ForwardAgent != "" && !strings.EqualFold(sshHost.ForwardAgent, "no")
+
 	return models.Host{
-		Alias:        sshHost.Host,
-		Hostname:     hostname,
-		User:         user,
-		Port:         port,
-		Tags:         tags,
-		IdentityFile: sshHost.IdentityFile,
-		ProxyJump:    sshHost.ProxyJump,
+		Alias:               sshHost.Host,
+		Hostname:            hostname,
+		User:                sshHost.User, // Left empty when unset, so ssh applies its defaults
+		Port:                sshHost.Port,
+		Tags:                tags,
+		IdentityFile:        sshHost.IdentityFile,
+		ProxyJump:           sshHost.ProxyJump,
+		LocalForwards:       sshHost.LocalForwards,
+		RemoteForwards:      sshHost.RemoteForwards,
+		DynamicForwards:     sshHost.DynamicForwards,
+		ForwardAgent:        forwardAgent,
+		ServerAliveInterval: sshHost.ServerAliveInterval,
+		Options:             sshHost.Options,
 	}
 }
 
-// LoadHostsFromSSHConfig loads all hosts from SSH config
-func LoadHostsFromSSHConfig() ([]models.Host, error) {
-	sshHosts, err := ParseSSHConfig()
+// LoadHostsFromSSHConfig loads all hosts from the SSH config file at configPath
+func LoadHostsFromSSHConfig(configPath string) ([]models.Host, error) {
+	sshHosts, err := ParseSSHConfig(configPath)
 	if err != nil {
 		return nil, err
 	}
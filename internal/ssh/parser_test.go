@@ -0,0 +1,238 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertToHostLeavesUserAndPortUnset(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	path := writeConfig(t, dir, "config",
+		"Host bare\n    HostName 10.0.0.1\n\nHost full\n    HostName 10.0.0.2\n    User admin\n    Port 2222\n")
+
+	tests := []struct {
+		alias, user, port string
+	}{
+		{"bare", "", ""},
+		{"full", "admin", "2222"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			host := ConvertToHost(loadHost(t, path, tt.alias))
+			if host.User != tt.user || host.Port != tt.port {
+				t.Errorf("got user %q port %q, want %q %q", host.User, host.Port, tt.user, tt.port)
+			}
+		})
+	}
+}
+
+func TestApplyResolvedSkipsSSHDefaults(t *testing.T) {
+	defaultUser := defaultSSHUser()
+	if defaultUser == "" {
+		t.Skip("current user unknown")
+	}
+
+	tests := []struct {
+		name       string
+		user, port string // Parsed from the config file
+		resolved   ResolvedHost
+		wantUser   string
+		wantPort   string
+	}{
+		{"defaults stay unset", "", "", ResolvedHost{User: defaultUser, Port: "22"}, "", ""},
+		{"set by Match", "", "", ResolvedHost{User: "ops", Port: "2222"}, "ops", "2222"},
+		{"set in the file", defaultUser, "22", ResolvedHost{User: defaultUser, Port: "22"}, defaultUser, "22"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := ConvertToHost(SSHConfigHost{Host: "web", User: tt.user, Port: tt.port})
+			applyResolved(&host, tt.resolved)
+			if host.User != tt.wantUser || host.Port != tt.wantPort {
+				t.Errorf("got user %q port %q, want %q %q", host.User, host.Port, tt.wantUser, tt.wantPort)
+			}
+		})
+	}
+}
+
+// parseTestConfig writes files to a temporary ~/.ssh and parses its config.
+// HOME in an IdentityFile is replaced with the temporary home directory.
+func parseTestConfig(t *testing.T, files map[string]string) []SSHConfigHost {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(filepath.Join(sshDir, "conf.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		writeConfig(t, sshDir, name, content)
+	}
+
+	hosts, err := ParseSSHConfig("~/.ssh/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range hosts {
+		hosts[i].IdentityFile = strings.Replace(hosts[i].IdentityFile, home, "HOME", 1)
+	}
+	return hosts
+}
+
+func TestParseSSHConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // Relative to ~/.ssh; "config" is parsed
+		want  []SSHConfigHost
+	}{
+		{
+			name:  "several aliases on one Host line",
+			files: map[string]string{"config": "Host web web.internal\n    HostName 10.0.0.1\n    User admin\n"},
+			want: []SSHConfigHost{
+				{Host: "web", HostName: "10.0.0.1", User: "admin"},
+				{Host: "web.internal", HostName: "10.0.0.1", User: "admin"},
+			},
+		},
+		{
+			name: "first value wins",
+			files: map[string]string{"config": "Port 2200\n\nHost *\n    User nobody\n\nHost web\n    User admin\n    Port 22\n" +
+				"    IdentityFile ~/.ssh/web\n    LocalForward 8080 localhost:80\n\nHost w*\n    LocalForward 9090 localhost:90\n    IdentityFile ~/.ssh/other\n"},
+			want: []SSHConfigHost{{
+				Host:          "web",
+				User:          "nobody",
+				Port:          "2200",
+				IdentityFile:  "HOME/.ssh/web",
+				LocalForwards: []string{"8080 localhost:80", "9090 localhost:90"},
+			}},
+		},
+		{
+			name:  "Host * after the host",
+			files: map[string]string{"config": "Host web\n    User admin\n\nHost *\n    User nobody\n    ServerAliveInterval 30\n"},
+			want:  []SSHConfigHost{{Host: "web", User: "admin", ServerAliveInterval: "30"}},
+		},
+		{
+			name: "patterns and negation",
+			files: map[string]string{"config": "Host web1 web22 db\n    HostName %h.internal\n\nHost * !db\n    User ops\n\n" +
+				"Host web?\n    Port 2222\n\nHost !web1 *\n    ProxyJump bastion\n"},
+			want: []SSHConfigHost{
+				{Host: "web1", HostName: "%h.internal", User: "ops", Port: "2222"},
+				{Host: "web22", HostName: "%h.internal", User: "ops", ProxyJump: "bastion"},
+				{Host: "db", HostName: "%h.internal", ProxyJump: "bastion"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTestConfig(t, tt.files)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSSHConfigInclude(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // Relative to ~/.ssh; "config" is parsed
+		want  []SSHConfigHost
+	}{
+		{
+			name: "relative include with a glob",
+			files: map[string]string{
+				"config":             "Include conf.d/*.conf\n\nHost main\n    HostName 10.0.0.1\n",
+				"conf.d/b.conf":      "Host b\n    HostName 10.0.0.3\n",
+				"conf.d/a.conf":      "Host a\n    HostName 10.0.0.2\n",
+				"conf.d/ignored.txt": "Host ignored\n",
+			},
+			want: []SSHConfigHost{
+				{Host: "a", HostName: "10.0.0.2"},
+				{Host: "b", HostName: "10.0.0.3"},
+				{Host: "main", HostName: "10.0.0.1"},
+			},
+		},
+		{
+			name: "include inside a Host block",
+			files: map[string]string{
+				"config":   "Host web\n    Include ~/.ssh/web.conf missing.conf\n    User admin\n",
+				"web.conf": "HostName 10.0.0.1\nPort 2222\n",
+			},
+			want: []SSHConfigHost{{Host: "web", HostName: "10.0.0.1", User: "admin", Port: "2222"}},
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"config":    "Include loop.conf\n\nHost a\n    HostName 10.0.0.1\n",
+				"loop.conf": "Include config loop.conf\n\nHost b\n    HostName 10.0.0.2\n",
+			},
+			want: []SSHConfigHost{
+				{Host: "b", HostName: "10.0.0.2"},
+				{Host: "a", HostName: "10.0.0.1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTestConfig(t, tt.files)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSSHConfigIncludeDepth(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Each file includes the next, one more level than OpenSSH allows
+	for i := 0; i <= maxIncludeDepth+1; i++ {
+		writeConfig(t, home, fmt.Sprintf("config%d", i), fmt.Sprintf("Include %s/config%d\n", home, i+1))
+	}
+	_, err := ParseSSHConfig(filepath.Join(home, "config0"))
+	if err == nil || !strings.Contains(err.Error(), "too many nested includes") {
+		t.Errorf("got error %v, want one about nested includes", err)
+	}
+
+	// The deepest include OpenSSH allows is fine
+	writeConfig(t, home, fmt.Sprintf("config%d", maxIncludeDepth), "Host deep\n    HostName 10.0.0.1\n")
+	hosts, err := ParseSSHConfig(filepath.Join(home, "config0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Host != "deep" {
+		t.Errorf("got %+v, want the deep host", hosts)
+	}
+}
+
+func TestMatchesHost(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		alias    string
+		want     bool
+	}{
+		{[]string{"web"}, "web", true},
+		{[]string{"WEB"}, "web", true},
+		{[]string{"db", "web"}, "web", true},
+		{[]string{"web"}, "web1", false},
+		{[]string{"web*"}, "web1", true},
+		{[]string{"web?"}, "web12", false},
+		{[]string{"*.example.com"}, "a.b.example.com", true},
+		{[]string{"*", "!db"}, "db", false},
+		{[]string{"!db", "*"}, "web", true},
+		{[]string{"!db"}, "web", false}, // A negation alone matches nothing
+		{[]string{"*", "!web*"}, "web1", false},
+		{nil, "web", false}, // Match blocks
+	}
+	for _, tt := range tests {
+		if got := matchesHost(tt.patterns, tt.alias); got != tt.want {
+			t.Errorf("matchesHost(%q, %q) = %v, want %v", tt.patterns, tt.alias, got, tt.want)
+		}
+	}
+}
@@ -1,51 +1,199 @@
 package ssh
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
 	"os/exec"
 	"sshbuddy/pkg/models"
 	"strings"
+	"time"
 )
 
 // PingResult contains the result of a ping operation
 type PingResult struct {
 	Host     models.Host
-	Status   bool   // true if reachable
-	PingTime string // ping time in ms
+	Status   bool          // true if reachable
+	PingTime string        // ping time in ms
+	Latency  time.Duration // Measured latency (connect time for TCP probes)
+	Banner   string        // SSH server banner, for the "ssh" method
+	Method   string        // Method used for the probe
+	Via      string        // First ProxyJump hop, if the host was probed through a bastion
+	Bastion  bool          // Whether the first hop answered (only meaningful when Via is set)
+	Err      error         // Why the host is considered unreachable
 }
 
-// Ping checks if a host is reachable using a simple ping
-func Ping(host models.Host) PingResult {
-	// Use ping with 1 count and 1 second timeout
-	cmd := exec.Command("ping", "-c", "1", "-W", "1", host.Hostname)
+// BastionDown reports whether the host could not be checked because its
+// jump host is unreachable
+func (r PingResult) BastionDown() bool {
+	return r.Via != "" && !r.Bastion
+}
+
+// PingOptions controls how a host is probed
+type PingOptions struct {
+	Method      string        // Default method; a host's PingMethod takes precedence
+	Timeout     time.Duration // Timeout for the whole probe
+	Concurrency int           // Maximum probes running at once in PingAll
+	ConfigPaths []string      // SSH config files jump host aliases are looked up in
+}
+
+// NewPingOptions builds probe options from the ping and SSH sections of the config
+func NewPingOptions(cfg *models.Config) PingOptions {
+	return PingOptions{
+		Method:      cfg.Ping.Method,
+		Timeout:     time.Duration(cfg.Ping.Timeout()) * time.Millisecond,
+		Concurrency: cfg.Ping.Workers(),
+		ConfigPaths: cfg.SSH.Paths(),
+	}
+}
+
+// Ping checks if a host is reachable. By default it opens a TCP connection to
+// the host's SSH port; the "ssh" method additionally reads the server's
+// SSH-2.0 banner and "icmp" uses the system ping command. Hosts with a
+// ProxyJump are checked through their jump hosts (see probeViaJump), and
+// docker hosts by asking docker whether their container is running.
+func Ping(host models.Host, opts PingOptions) PingResult {
+	return PingContext(context.Background(), host, opts)
+}
+
+// PingContext is like Ping but aborts the probe when ctx is cancelled
+func PingContext(ctx context.Context, host models.Host, opts PingOptions) PingResult {
+	method := models.PingConfig{Method: opts.Method}.MethodFor(host)
+	if opts.Timeout <= 0 {
+		opts.Timeout = models.DefaultPingTimeoutMs * time.Millisecond
+	}
+
+	var result PingResult
+	switch {
+	case host.IsDocker():
+		result = probeContainer(ctx, host, opts.Timeout)
+		method = models.ConnectModeDocker
+	case host.ProxyJump != "" && host.ProxyJump != "none":
+		result = probeViaJump(ctx, host, opts, method == models.PingMethodSSH)
+	case method == models.PingMethodICMP:
+		result = pingICMP(ctx, host, opts.Timeout)
+	default:
+		result = probeTCP(ctx, host, opts.Timeout, method == models.PingMethodSSH)
+	}
+
+	result.Host = host
+	result.Method = method
+	if result.Status && result.PingTime == "" {
+		result.PingTime = formatLatency(result.Latency)
+	}
+	return result
+}
+
+// probeTCP dials the host's SSH port, optionally waiting for the SSH banner
+func probeTCP(ctx context.Context, host models.Host, timeout time.Duration, readBanner bool) PingResult {
+	port := host.Port
+	if port == "" {
+		port = "22"
+	}
+
+	deadline := time.Now().Add(timeout)
+	start := time.Now()
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host.Hostname, port))
+	if err != nil {
+		return PingResult{Err: err}
+	}
+	defer conn.Close()
+	latency := time.Since(start)
+
+	if !readBanner {
+		return PingResult{Status: true, Latency: latency}
+	}
+
+	banner, err := readSSHBanner(conn, deadline)
+	if err != nil {
+		return PingResult{Latency: latency, Err: err}
+	}
+	return PingResult{Status: true, Latency: latency, Banner: banner}
+}
+
+// readSSHBanner reads the server identification string. RFC 4253 allows
+// other lines before it, so those are skipped until the deadline.
+func readSSHBanner(conn net.Conn, deadline time.Time) (string, error) {
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return "", err
+	}
+
+	reader := bufio.NewReaderSize(conn, 256)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "SSH-") {
+			return line, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("no SSH banner: %w", err)
+		}
+	}
+}
+
+// pingICMP runs the system ping command once
+func pingICMP(ctx context.Context, host models.Host, timeout time.Duration) PingResult {
+	seconds := int(timeout.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	cmd := exec.CommandContext(ctx, "ping", "-c", "1", "-W", fmt.Sprint(seconds), host.Hostname)
 	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return PingResult{Err: err}
+	}
 
-	// Parse ping time from output
+	// Extract time from ping output (e.g., "time=12.3 ms")
 	pingTime := ""
-	if err == nil {
-		// Extract time from ping output (e.g., "time=12.3 ms")
-		outputStr := string(output)
-
-		// Try to find "time=" pattern
-		if idx := strings.Index(outputStr, "time="); idx != -1 {
-			timeStr := outputStr[idx+5:]
-			// Find the end of the time value (space or newline)
-			endIdx := strings.IndexAny(timeStr, " \n\r")
-			if endIdx != -1 {
-				timeValue := strings.TrimSpace(timeStr[:endIdx])
-				pingTime = timeValue
-				// Add "ms" if not already present
-				if !strings.HasSuffix(pingTime, "ms") {
-					pingTime = pingTime + "ms"
-				}
+	outputStr := string(output)
+	if idx := strings.Index(outputStr, "time="); idx != -1 {
+		timeStr := outputStr[idx+5:]
+		// Find the end of the time value (space or newline)
+		endIdx := strings.IndexAny(timeStr, " \n\r")
+		if endIdx != -1 {
+			pingTime = strings.TrimSpace(timeStr[:endIdx])
+			// Add "ms" if not already present
+			if !strings.HasSuffix(pingTime, "ms") {
+				pingTime = pingTime + "ms"
 			}
 		}
 	}
 
-	return PingResult{
-		Host:     host,
-		Status:   err == nil,
-		PingTime: pingTime,
+	var latency time.Duration
+	if ms, err := time.ParseDuration(pingTime); err == nil {
+		latency = ms
+	}
+
+	return PingResult{Status: true, PingTime: pingTime, Latency: latency}
+}
+
+// probeContainer asks docker whether the host's container is running
+func probeContainer(ctx context.Context, host models.Host, timeout time.Duration) PingResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", host.Hostname)
+	output, err := cmd.Output()
+	latency := time.Since(start)
+	if err != nil {
+		return PingResult{Err: fmt.Errorf("docker inspect: %w", err)}
+	}
+	if strings.TrimSpace(string(output)) != "true" {
+		return PingResult{Latency: latency, Err: fmt.Errorf("container %s is not running", host.Hostname)}
+	}
+	return PingResult{Status: true, Latency: latency}
+}
+
+// formatLatency formats a latency the way ping reports it, e.g. "12.3ms"
+func formatLatency(latency time.Duration) string {
+	ms := float64(latency) / float64(time.Millisecond)
+	if ms < 10 {
+		return fmt.Sprintf("%.1fms", ms)
 	}
+	return fmt.Sprintf("%.0fms", ms)
 }
 
 // GetHostStatus returns a visual indicator for host status
@@ -58,5 +206,5 @@ func GetHostStatus(status bool) string {
 
 // GetHostKey creates a unique key for a host (for tracking ping status)
 func GetHostKey(host models.Host) string {
-	return strings.ToLower(host.Hostname + ":" + host.User)
+	return strings.ToLower(host.Hostname + ":" + host.Port + ":" + host.User)
 }
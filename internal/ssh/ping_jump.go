@@ -0,0 +1,197 @@
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sshbuddy/pkg/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// probeViaJump checks a host that is only reachable through ProxyJump. The
+// first hop is probed directly; if it answers, ssh opens a -W tunnel through
+// the chain to the target's SSH port and the first line the target sends
+// (its banner) proves it is reachable. The tunnel needs non-interactive
+// authentication to the jump hosts (keys or agent), as BatchMode is used.
+// Jump host aliases are looked up in the configured SSH config files.
+func probeViaJump(ctx context.Context, host models.Host, opts PingOptions, requireBanner bool) PingResult {
+	timeout := opts.Timeout
+	hops := splitJumpChain(host.ProxyJump)
+	if len(hops) == 0 {
+		return probeTCP(ctx, host, timeout, requireBanner)
+	}
+	result := PingResult{Via: hops[0]}
+
+	// A dead bastion means the target's status is unknown, not down. A first
+	// hop that has its own ProxyJump can't be probed directly, so the tunnel
+	// below is left to decide.
+	deadline := time.Now().Add(timeout)
+	if first, direct := resolveJumpHop(ctx, opts.ConfigPaths, hops[0]); direct {
+		bastion := probeTCP(ctx, first, timeout, false)
+		if !bastion.Status {
+			result.Err = fmt.Errorf("jump host %s: %w", hops[0], bastion.Err)
+			return result
+		}
+	}
+	result.Bastion = true
+
+	port := host.Port
+	if port == "" {
+		port = "22"
+	}
+
+	// Give the tunnel at least a few seconds; it includes the bastion handshake
+	tunnelTimeout := time.Until(deadline)
+	if tunnelTimeout < 5*time.Second {
+		tunnelTimeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, tunnelTimeout)
+	defer cancel()
+
+	connectTimeout := int(tunnelTimeout.Round(time.Second) / time.Second)
+	var args []string
+	if configPath := jumpConfigPath(opts.ConfigPaths, hops[len(hops)-1]); configPath != "" {
+		args = append(args, "-F", configPath)
+	}
+	args = append(args,
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout="+strconv.Itoa(connectTimeout),
+		"-W", net.JoinHostPort(host.Hostname, port),
+	)
+	if len(hops) > 1 {
+		args = append(args, "-J", strings.Join(hops[:len(hops)-1], ","))
+	}
+	args = append(args, hops[len(hops)-1])
+
+	start := time.Now()
+	banner, err := readTunnelBanner(ctx, exec.CommandContext(ctx, "ssh", args...))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if requireBanner && !strings.HasPrefix(banner, "SSH-") {
+		result.Err = fmt.Errorf("no SSH banner from %s through %s", host.Hostname, host.ProxyJump)
+		return result
+	}
+
+	result.Status = true
+	result.Latency = time.Since(start)
+	if strings.HasPrefix(banner, "SSH-") {
+		result.Banner = banner
+	}
+	return result
+}
+
+// readTunnelBanner starts cmd and returns the first line it prints
+func readTunnelBanner(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if line != "" {
+		return line, nil
+	}
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("tunnel timed out")
+	}
+
+	// The tunnel closed without data; ssh's stderr explains why
+	_ = cmd.Wait()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return "", fmt.Errorf("tunnel failed: %s", msg)
+	}
+	return "", fmt.Errorf("tunnel closed: %w", err)
+}
+
+// splitJumpChain splits a ProxyJump value into its hops
+func splitJumpChain(proxyJump string) []string {
+	var hops []string
+	for _, hop := range strings.Split(proxyJump, ",") {
+		if hop = strings.TrimSpace(hop); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// resolveJumpHop turns a "[user@]host[:port]" hop into the address to probe.
+// The host part may be an alias from one of the SSH config files at
+// configPaths, so it is resolved with `ssh -G` when possible; an explicit port
+// in the hop always wins. direct is false if the hop is itself configured
+// with a ProxyJump.
+func resolveJumpHop(ctx context.Context, configPaths []string, hop string) (target models.Host, direct bool) {
+	if at := strings.LastIndex(hop, "@"); at != -1 {
+		hop = hop[at+1:]
+	}
+
+	target = models.Host{Hostname: hop}
+	if h, p, err := net.SplitHostPort(hop); err == nil {
+		target.Hostname = h
+		target.Port = p
+	} else if strings.HasPrefix(hop, "[") && strings.HasSuffix(hop, "]") {
+		target.Hostname = strings.Trim(hop, "[]")
+	}
+
+	configPath := jumpConfigPath(configPaths, target.Hostname)
+	if configPath == "" {
+		configPath = models.DefaultSSHConfigPath
+	}
+	if resolved, err := ResolveHost(ctx, configPath, target.Hostname); err == nil {
+		if resolved.HostName != "" {
+			target.Hostname = resolved.HostName
+		}
+		if target.Port == "" {
+			target.Port = resolved.Port
+		}
+		if resolved.ProxyJump != "" {
+			return target, false
+		}
+	}
+	return target, true
+}
+
+// jumpConfigPath returns the SSH config file ssh needs to be pointed at with
+// -F to know the jump host hop: the first of configPaths with a Host block
+// (other than Host *) for it. It is "" for the default ~/.ssh/config, which
+// ssh reads anyway, and for hops no config file defines.
+func jumpConfigPath(configPaths []string, hop string) string {
+	if at := strings.LastIndex(hop, "@"); at != -1 {
+		hop = hop[at+1:]
+	}
+	if h, _, err := net.SplitHostPort(hop); err == nil {
+		hop = h
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	for _, path := range configPaths {
+		p, err := parseSSHConfig(path)
+		if err != nil || !p.definesHost(hop) {
+			continue
+		}
+		if path = expandHome(path, homeDir); readsSystemConfig(path, homeDir) {
+			return ""
+		}
+		return path
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package ssh
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestJumpConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	writeConfig(t, dir, "config", "Host *\n    ServerAliveInterval 30\n")
+	work := writeConfig(t, dir, "work.conf", "Host bastion *.corp !skip.corp\n    HostName 10.0.0.1\n")
+	if err := os.Mkdir(filepath.Join(dir, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(dir, ".ssh"), "config", "Host home-jump\n    HostName 192.168.1.1\n")
+
+	paths := []string{"~/.ssh/config", filepath.Join(dir, "config"), work}
+	tests := []struct {
+		hop  string
+		want string
+	}{
+		{"bastion", work},
+		{"ops@bastion:2222", work},
+		{"db.corp", work},
+		{"skip.corp", ""}, // Excluded by the negated pattern
+		{"unknown", ""},   // Host * alone doesn't define it
+		{"home-jump", ""}, // ssh reads the default config anyway
+		{"10.0.0.5", ""},  // Plain addresses need no config
+	}
+	for _, tt := range tests {
+		if got := jumpConfigPath(paths, tt.hop); got != tt.want {
+			t.Errorf("jumpConfigPath(%q) = %q, want %q", tt.hop, got, tt.want)
+		}
+	}
+}
+
+func TestResolveJumpHopUsesConfiguredPaths(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	work := writeConfig(t, dir, "work.conf", "Host bastion\n    HostName 10.0.0.1\n    Port 2222\n\nHost inner\n    HostName 10.0.0.2\n    ProxyJump bastion\n")
+	paths := []string{"~/.ssh/config", work}
+
+	target, direct := resolveJumpHop(context.Background(), paths, "ops@bastion")
+	if target.Hostname != "10.0.0.1" || target.Port != "2222" || !direct {
+		t.Errorf("bastion resolved to %+v (direct %v), want 10.0.0.1:2222", target, direct)
+	}
+	if _, direct := resolveJumpHop(context.Background(), paths, "inner"); direct {
+		t.Error("inner has a ProxyJump and can't be probed directly")
+	}
+}
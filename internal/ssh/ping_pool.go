@@ -0,0 +1,54 @@
+package ssh
+
+import (
+	"context"
+	"sshbuddy/pkg/models"
+	"sync"
+)
+
+// PingAll probes hosts with at most opts.Concurrency probes running at once
+// and sends each result to out as soon as it is available. It returns, closing
+// out, when every host has been probed or ctx is cancelled; results of probes
+// aborted by cancellation are not sent.
+func PingAll(ctx context.Context, hosts []models.Host, opts PingOptions, out chan<- PingResult) {
+	defer close(out)
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = models.DefaultPingConcurrency
+	}
+	if workers > len(hosts) {
+		workers = len(hosts)
+	}
+
+	jobs := make(chan models.Host)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				result := PingContext(ctx, host, opts)
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, host := range hosts {
+		select {
+		case jobs <- host:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
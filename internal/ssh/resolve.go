@@ -0,0 +1,295 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sshbuddy/pkg/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resolveWorkers limits how many `ssh -G` processes run at once
+const resolveWorkers = 8
+
+// resolveTimeout bounds a single `ssh -G`, which can hang on a Match exec
+// or a slow canonicalization lookup
+const resolveTimeout = 5 * time.Second
+
+// systemSSHConfig is read by ssh after the user's config unless -F is given
+const systemSSHConfig = "/etc/ssh/ssh_config"
+
+// ResolvedHost holds the effective settings OpenSSH reports for an alias
+type ResolvedHost struct {
+	HostName     string `json:"hostname"`
+	User         string `json:"user"`
+	Port         string `json:"port"`
+	IdentityFile string `json:"identityFile,omitempty"`
+	ProxyJump    string `json:"proxyJump,omitempty"`
+}
+
+// resolveCacheEntry caches resolved hosts for one config file. The entry is
+// only valid while the fingerprint of the config and its includes matches.
+type resolveCacheEntry struct {
+	Fingerprint string                  `json:"fingerprint"`
+	Hosts       map[string]ResolvedHost `json:"hosts"`
+}
+
+// ResolveHost runs `ssh -G` for alias and returns the canonical settings.
+// configPath is passed with -F unless it is the default ~/.ssh/config, so the
+// system-wide config is honoured as well in the common case.
+func ResolveHost(ctx context.Context, configPath, alias string) (ResolvedHost, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ResolvedHost{}, err
+	}
+
+	var args []string
+	configPath = expandHome(configPath, homeDir)
+	if !readsSystemConfig(configPath, homeDir) {
+		args = append(args, "-F", configPath)
+	}
+	args = append(args, "-G", alias)
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return ResolvedHost{}, fmt.Errorf("ssh -G %s: timed out after %s", alias, resolveTimeout)
+		}
+		return ResolvedHost{}, fmt.Errorf("ssh -G %s: %w: %s", alias, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseSSHGOutput(output, homeDir), nil
+}
+
+// readsSystemConfig reports whether ssh reads the system-wide config for
+// configPath, i.e. whether it is the default config and no -F is needed
+func readsSystemConfig(configPath, homeDir string) bool {
+	return configPath == filepath.Join(homeDir, ".ssh", "config")
+}
+
+// parseSSHGOutput extracts the fields sshbuddy models from `ssh -G` output
+func parseSSHGOutput(output []byte, homeDir string) ResolvedHost {
+	var resolved ResolvedHost
+	var identityFiles []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "hostname":
+			resolved.HostName = value
+		case "user":
+			resolved.User = value
+		case "port":
+			resolved.Port = value
+		case "identityfile":
+			identityFiles = append(identityFiles, value)
+		case "proxyjump":
+			if value != "none" {
+				resolved.ProxyJump = value
+			}
+		}
+	}
+
+	// ssh -G lists OpenSSH's built-in key names when no IdentityFile is
+	// configured; those are not a host setting, so only keep explicit ones
+	for _, identityFile := range identityFiles {
+		if !isDefaultIdentityFile(identityFile) {
+			resolved.IdentityFile = expandHome(identityFile, homeDir)
+			break
+		}
+	}
+
+	return resolved
+}
+
+// isDefaultIdentityFile reports whether path is one of the keys OpenSSH tries
+// when no IdentityFile is configured
+func isDefaultIdentityFile(path string) bool {
+	switch path {
+	case "~/.ssh/id_rsa", "~/.ssh/id_ecdsa", "~/.ssh/id_ecdsa_sk", "~/.ssh/id_ed25519",
+		"~/.ssh/id_ed25519_sk", "~/.ssh/id_xmss", "~/.ssh/id_dsa":
+		return true
+	}
+	return false
+}
+
+// LoadHostsFromSSHConfigResolved loads hosts like LoadHostsFromSSHConfig, then
+// fills HostName, User, Port, IdentityFile and ProxyJump from `ssh -G` so that
+// Match blocks, tokens and canonicalization behave exactly as in OpenSSH.
+//
+// Results are cached in cachePath and reused until the config file, any of
+// its includes or, for the default config, the system-wide config changes.
+// Hosts that fail to resolve keep their parsed values.
+func LoadHostsFromSSHConfigResolved(ctx context.Context, configPath, cachePath string) ([]models.Host, error) {
+	p, err := parseSSHConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []models.Host
+	for _, sshHost := range p.resolveHosts() {
+		hosts = append(hosts, ConvertToHost(sshHost))
+	}
+	if len(hosts) == 0 {
+		return hosts, nil
+	}
+
+	cache := loadResolveCache(cachePath)
+	cacheKey := expandHome(configPath, p.homeDir)
+	files := p.files
+	if readsSystemConfig(cacheKey, p.homeDir) {
+		files = append(files, systemConfigFiles()...)
+	}
+	fingerprint := configFingerprint(files)
+
+	entry, ok := cache[cacheKey]
+	if !ok || entry.Fingerprint != fingerprint {
+		entry = resolveCacheEntry{
+			Fingerprint: fingerprint,
+			Hosts:       make(map[string]ResolvedHost),
+		}
+	}
+
+	// Resolve aliases missing from the cache with a bounded number of workers
+	var missing []string
+	for _, host := range hosts {
+		if _, ok := entry.Hosts[host.Alias]; !ok {
+			missing = append(missing, host.Alias)
+		}
+	}
+
+	if len(missing) > 0 {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		aliases := make(chan string)
+
+		for i := 0; i < resolveWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for alias := range aliases {
+					resolved, err := ResolveHost(ctx, configPath, alias)
+					if err != nil {
+						continue
+					}
+					mu.Lock()
+					entry.Hosts[alias] = resolved
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, alias := range missing {
+			aliases <- alias
+		}
+		close(aliases)
+		wg.Wait()
+
+		cache[cacheKey] = entry
+		saveResolveCache(cachePath, cache)
+	}
+
+	for i := range hosts {
+		if resolved, ok := entry.Hosts[hosts[i].Alias]; ok {
+			applyResolved(&hosts[i], resolved)
+		}
+	}
+
+	return hosts, nil
+}
+
+// applyResolved overrides host fields with non-empty resolved values. ssh -G
+// always reports a user and port; OpenSSH's defaults for them are not applied
+// to hosts that leave them unset, so they stay empty like in ConvertToHost.
+func applyResolved(host *models.Host, resolved ResolvedHost) {
+	if resolved.HostName != "" {
+		host.Hostname = resolved.HostName
+	}
+	if resolved.User != "" && (host.User != "" || resolved.User != defaultSSHUser()) {
+		host.User = resolved.User
+	}
+	if resolved.Port != "" && (host.Port != "" || resolved.Port != "22") {
+		host.Port = resolved.Port
+	}
+	if resolved.IdentityFile != "" {
+		host.IdentityFile = resolved.IdentityFile
+	}
+	if resolved.ProxyJump != "" {
+		host.ProxyJump = resolved.ProxyJump
+	}
+}
+
+// defaultSSHUser returns the user ssh connects as when none is configured
+func defaultSSHUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// systemConfigFiles returns the system-wide config and the files it includes
+func systemConfigFiles() []string {
+	p, err := parseSSHConfig(systemSSHConfig)
+	if err != nil {
+		return []string{systemSSHConfig}
+	}
+	return p.files
+}
+
+// configFingerprint identifies the current state of a set of config files by
+// their paths, sizes and modification times
+func configFingerprint(files []string) string {
+	var sb strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano()))
+	}
+	return sb.String()
+}
+
+// loadResolveCache reads the resolve cache, returning an empty cache on any error
+func loadResolveCache(cachePath string) map[string]resolveCacheEntry {
+	cache := make(map[string]resolveCacheEntry)
+	if cachePath == "" {
+		return cache
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return make(map[string]resolveCacheEntry)
+	}
+	return cache
+}
+
+// saveResolveCache writes the resolve cache; failures only cost a re-resolve
+func saveResolveCache(cachePath string, cache map[string]resolveCacheEntry) {
+	if cachePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, data, 0644)
+}
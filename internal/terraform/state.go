@@ -0,0 +1,243 @@
+// Package terraform reads the instances of a Terraform state as hosts
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sshbuddy/internal/inventory"
+	"sshbuddy/pkg/models"
+	"strings"
+)
+
+// Instance is one instance of a managed resource
+type Instance struct {
+	Address    string         // e.g. module.app.aws_instance.web[0]
+	Type       string         // Resource type, e.g. aws_instance
+	Name       string         // Resource name, e.g. web
+	Index      any            // count index (number) or for_each key (string), nil if neither
+	Attributes map[string]any // Attribute values
+}
+
+// stateFile covers both the state file format (version 4) and the output of
+// `terraform show -json`
+type stateFile struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+	Values *struct {
+		RootModule showModule `json:"root_module"`
+	} `json:"values"`
+}
+
+// showModule is a module in the output of `terraform show -json`
+type showModule struct {
+	Resources []struct {
+		Address string         `json:"address"`
+		Mode    string         `json:"mode"`
+		Type    string         `json:"type"`
+		Name    string         `json:"name"`
+		Index   any            `json:"index"`
+		Values  map[string]any `json:"values"`
+	} `json:"resources"`
+	ChildModules []showModule `json:"child_modules"`
+}
+
+// ParseState returns the instances of the managed resources in a state file
+// or in the output of `terraform show -json`
+func ParseState(data []byte) ([]Instance, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keep ports and IDs as written
+	var state stateFile
+	if err := decoder.Decode(&state); err != nil {
+		return nil, fmt.Errorf("not a Terraform state: %w", err)
+	}
+
+	var instances []Instance
+	if state.Values != nil {
+		var walk func(module showModule)
+		walk = func(module showModule) {
+			for _, r := range module.Resources {
+				if r.Mode != "managed" {
+					continue
+				}
+				instances = append(instances, Instance{
+					Address:    r.Address,
+					Type:       r.Type,
+					Name:       r.Name,
+					Index:      r.Index,
+					Attributes: r.Values,
+				})
+			}
+			for _, child := range module.ChildModules {
+				walk(child)
+			}
+		}
+		walk(state.Values.RootModule)
+		return instances, nil
+	}
+
+	if state.Version != 0 && state.Version < 4 {
+		return nil, fmt.Errorf("state format version %d is not supported, upgrade to Terraform 0.12 or later", state.Version)
+	}
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		for _, inst := range r.Instances {
+			address := r.Type + "." + r.Name + indexSuffix(inst.IndexKey)
+			if r.Module != "" {
+				address = r.Module + "." + address
+			}
+			instances = append(instances, Instance{
+				Address:    address,
+				Type:       r.Type,
+				Name:       r.Name,
+				Index:      inst.IndexKey,
+				Attributes: inst.Attributes,
+			})
+		}
+	}
+	return instances, nil
+}
+
+// indexSuffix renders the index of an instance address
+func indexSuffix(index any) string {
+	switch v := index.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("[%q]", v)
+	default:
+		return fmt.Sprintf("[%v]", v)
+	}
+}
+
+// Default JSONPaths of the alias and hostname, tried in order. They cover
+// the attributes of the common providers' instance resources.
+var (
+	defaultAliasPaths = []string{
+		"$.tags.Name",
+		"$.labels.name",
+		"$.name",
+	}
+	defaultHostnamePaths = []string{
+		"$.public_ip",
+		"$.ipv4_address",
+		"$.public_ip_address",
+		"$.access_ip_v4",
+		"$.ip_address",
+		"$.network_interface[0].access_config[0].nat_ip",
+		"$.default_ip_address",
+		"$.private_ip",
+		"$.private_ip_address",
+		"$.ipv4_address_private",
+		"$.network_interface[0].network_ip",
+		"$.network_interface[0].addresses[0]",
+	}
+)
+
+// MapHosts converts the instances of the configured resource types to hosts
+// using the field mapping of cfg. The alias and hostname fall back to the
+// usual name and IP attributes; an instance without a name is named after
+// its resource, and instances sharing a name are numbered. Instances without
+// an address (e.g. stopped ones) are skipped and counted.
+func MapHosts(cfg models.TerraformSourceConfig, instances []Instance) ([]models.Host, int, error) {
+	paths := make(map[string][]inventory.Path)
+	for _, field := range models.HTTPMappableFields {
+		exprs := []string{cfg.Fields[field]}
+		if strings.TrimSpace(cfg.Fields[field]) == "" {
+			switch field {
+			case "alias":
+				exprs = defaultAliasPaths
+			case "hostname":
+				exprs = defaultHostnamePaths
+			default:
+				continue
+			}
+		}
+		for _, expr := range exprs {
+			path, err := inventory.ParsePath(expr)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: field %s: %w", cfg.SourceName(), field, err)
+			}
+			paths[field] = append(paths[field], path)
+		}
+	}
+
+	types := make(map[string]bool)
+	for _, t := range cfg.Types() {
+		types[t] = true
+	}
+
+	hosts := []models.Host{}
+	skipped := 0
+	aliases := make(map[string]int) // Last number given to each alias
+	emitted := make(map[string]bool)
+	for _, inst := range instances {
+		if !types[inst.Type] {
+			continue
+		}
+		scalar := func(field string) string {
+			for _, path := range paths[field] {
+				if s := path.FirstString(inst.Attributes); s != "" {
+					return s
+				}
+			}
+			return ""
+		}
+
+		host := models.Host{
+			Alias:        scalar("alias"),
+			Hostname:     scalar("hostname"),
+			User:         scalar("user"),
+			Port:         scalar("port"),
+			IdentityFile: scalar("identity_file"),
+			ProxyJump:    scalar("proxy_jump"),
+			DefaultPath:  scalar("default_path"),
+		}
+		for _, path := range paths["tags"] {
+			host.Tags = append(host.Tags, path.Strings(inst.Attributes)...)
+		}
+		if host.Alias == "" {
+			host.Alias = instanceName(inst)
+		}
+		if host.User == "" {
+			host.User = cfg.User
+		}
+		if host.Hostname == "" {
+			skipped++
+			continue
+		}
+		// Instances sharing a name tag are numbered: web, web-2, web-3,
+		// skipping numbers taken by an instance named like that
+		if emitted[host.Alias] {
+			base := host.Alias
+			n := max(aliases[base], 1)
+			for emitted[host.Alias] {
+				n++
+				host.Alias = fmt.Sprintf("%s-%d", base, n)
+			}
+			aliases[base] = n
+		}
+		emitted[host.Alias] = true
+		hosts = append(hosts, host)
+	}
+	return hosts, skipped, nil
+}
+
+// instanceName names an instance after its resource and index, e.g. web-0
+func instanceName(inst Instance) string {
+	if inst.Index == nil {
+		return inst.Name
+	}
+	return fmt.Sprintf("%s-%v", inst.Name, inst.Index)
+}
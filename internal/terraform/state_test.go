@@ -0,0 +1,154 @@
+package terraform
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sshbuddy/pkg/models"
+)
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+		want  []Instance
+	}{
+		{
+			name: "state file",
+			state: `{"version": 4, "resources": [
+				{"mode": "data", "type": "aws_ami", "name": "ubuntu", "instances": [{"attributes": {"id": "ami-1"}}]},
+				{"mode": "managed", "type": "aws_instance", "name": "web", "instances": [
+					{"index_key": 0, "attributes": {"public_ip": "203.0.113.1"}},
+					{"index_key": 1, "attributes": {"public_ip": "203.0.113.2"}}
+				]},
+				{"module": "module.db", "mode": "managed", "type": "hcloud_server", "name": "db", "instances": [
+					{"index_key": "primary", "attributes": {"ipv4_address": "203.0.113.3"}}
+				]}
+			]}`,
+			want: []Instance{
+				{Address: "aws_instance.web[0]", Type: "aws_instance", Name: "web", Index: json.Number("0"), Attributes: map[string]any{"public_ip": "203.0.113.1"}},
+				{Address: "aws_instance.web[1]", Type: "aws_instance", Name: "web", Index: json.Number("1"), Attributes: map[string]any{"public_ip": "203.0.113.2"}},
+				{Address: `module.db.hcloud_server.db["primary"]`, Type: "hcloud_server", Name: "db", Index: "primary", Attributes: map[string]any{"ipv4_address": "203.0.113.3"}},
+			},
+		},
+		{
+			name: "terraform show -json",
+			state: `{"format_version": "1.0", "values": {"root_module": {
+				"resources": [
+					{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "values": {"public_ip": "203.0.113.1"}},
+					{"address": "data.aws_ami.ubuntu", "mode": "data", "type": "aws_ami", "name": "ubuntu", "values": {}}
+				],
+				"child_modules": [{"resources": [
+					{"address": "module.db.hcloud_server.db[0]", "mode": "managed", "type": "hcloud_server", "name": "db", "index": 0, "values": {"ipv4_address": "203.0.113.3"}}
+				]}]
+			}}}`,
+			want: []Instance{
+				{Address: "aws_instance.web", Type: "aws_instance", Name: "web", Attributes: map[string]any{"public_ip": "203.0.113.1"}},
+				{Address: "module.db.hcloud_server.db[0]", Type: "hcloud_server", Name: "db", Index: json.Number("0"), Attributes: map[string]any{"ipv4_address": "203.0.113.3"}},
+			},
+		},
+		{
+			name:  "empty state",
+			state: `{"version": 4, "resources": []}`,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseState([]byte(tt.state))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStateErrors(t *testing.T) {
+	tests := []struct {
+		name, state, wantErr string
+	}{
+		{"not JSON", "terraform {}", "not a Terraform state"},
+		{"old format", `{"version": 3, "modules": []}`, "version 3 is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseState([]byte(tt.state))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMapHosts(t *testing.T) {
+	instances := []Instance{
+		{Type: "aws_instance", Name: "web", Index: json.Number("0"), Attributes: map[string]any{
+			"tags": map[string]any{"Name": "frontend"}, "public_ip": "203.0.113.1", "private_ip": "10.0.0.1",
+		}},
+		{Type: "aws_instance", Name: "worker", Index: json.Number("0"), Attributes: map[string]any{"private_ip": "10.0.0.2"}},
+		{Type: "aws_instance", Name: "stopped", Attributes: map[string]any{"tags": map[string]any{"Name": "stopped"}}},
+		{Type: "aws_s3_bucket", Name: "assets", Attributes: map[string]any{"name": "assets"}},
+		{Type: "hcloud_server", Name: "db", Attributes: map[string]any{"name": "db", "ipv4_address": "203.0.113.3", "labels": map[string]any{"role": "db"}}},
+	}
+	cfg := models.TerraformSourceConfig{
+		Name:   "prod",
+		User:   "admin",
+		Fields: map[string]string{"user": "$.labels.user", "tags": "$.labels.role"},
+	}
+
+	hosts, skipped, err := MapHosts(cfg, instances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Host{
+		{Alias: "frontend", Hostname: "203.0.113.1", User: "admin"},
+		{Alias: "worker-0", Hostname: "10.0.0.2", User: "admin"},
+		{Alias: "db", Hostname: "203.0.113.3", User: "admin", Tags: []string{"db"}},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("got %+v\nwant %+v", hosts, want)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped %d instances, want 1", skipped)
+	}
+
+	cfg.Fields = map[string]string{"alias": "$.tags["}
+	if _, _, err := MapHosts(cfg, instances); err == nil {
+		t.Error("expected an error for a bad JSONPath")
+	}
+}
+
+func TestMapHostsNumbersSharedAliases(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"web", "web", "web"}, []string{"web", "web-2", "web-3"}},
+		{[]string{"web", "web", "web-2"}, []string{"web", "web-2", "web-2-2"}},
+		{[]string{"web-2", "web", "web"}, []string{"web-2", "web", "web-3"}},
+		{[]string{"web", "web-3", "web", "web", "web"}, []string{"web", "web-3", "web-2", "web-4", "web-5"}},
+	}
+	for _, tt := range tests {
+		var instances []Instance
+		for _, name := range tt.names {
+			instances = append(instances, Instance{Type: "aws_instance", Attributes: map[string]any{"tags": map[string]any{"Name": name}, "public_ip": "203.0.113.1"}})
+		}
+		hosts, _, err := MapHosts(models.TerraformSourceConfig{Name: "prod"}, instances)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var aliases []string
+		for _, host := range hosts {
+			aliases = append(aliases, host.Alias)
+		}
+		if !reflect.DeepEqual(aliases, tt.want) {
+			t.Errorf("names %q got aliases %q, want %q", tt.names, aliases, tt.want)
+		}
+	}
+}
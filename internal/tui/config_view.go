@@ -38,8 +38,8 @@ type ConfigViewModel struct {
 
 // NewConfigViewModel creates a new configuration view model
 func NewConfigViewModel() ConfigViewModel {
-	// Load current config
-	cfg, err := config.LoadConfig()
+	// Load current config; the settings don't need the hosts of other sources
+	cfg, err := config.LoadConfigRaw()
 	if err != nil {
 		cfg = &models.Config{
 			Hosts: []models.Host{},
@@ -68,7 +68,7 @@ func NewConfigViewModel() ConfigViewModel {
 		{
 			Name:         "SSH Config",
 			Enabled:      cfg.Sources.SSHConfigEnabled,
-			Description:  "Hosts from ~/.ssh/config",
+			Description:  "Hosts from " + strings.Join(cfg.SSH.Paths(), ", "),
 			Configurable: true,
 		},
 		{
@@ -77,12 +77,30 @@ func NewConfigViewModel() ConfigViewModel {
 			Description:  "Hosts from Termix API server",
 			Configurable: true,
 		},
+		{
+			Name:         "Known Hosts",
+			Enabled:      cfg.KnownHosts.Enabled,
+			Description:  "Discover hosts you connected to from known_hosts",
+			Configurable: true,
+		},
+		{
+			Name:         "Docker",
+			Enabled:      cfg.Docker.Enabled,
+			Description:  "Running containers, opened with docker exec",
+			Configurable: true,
+		},
 		{
 			Name:         "Theme",
 			Enabled:      true, // Always enabled, just shows current theme
 			Description:  fmt.Sprintf("Current: %s", GetCurrentTheme().Name),
 			Configurable: true,
 		},
+		{
+			Name:         "Ping",
+			Enabled:      true, // Always enabled, just shows the current method
+			Description:  pingMethodDescription(cfg.Ping),
+			Configurable: true,
+		},
 	}
 
 	// Create Termix input fields (only base URL, credentials are prompted when needed)
@@ -101,7 +119,7 @@ func NewConfigViewModel() ConfigViewModel {
 	// Config Path input
 	sshConfigInputs[0] = textinput.New()
 	sshConfigInputs[0].Placeholder = "~/.ssh/config (leave empty for default)"
-	sshConfigInputs[0].SetValue(cfg.SSH.ConfigPath)
+	sshConfigInputs[0].SetValue(strings.Join(append([]string{cfg.SSH.ConfigPath}, cfg.SSH.ConfigPaths...), ", "))
 	sshConfigInputs[0].CharLimit = 300
 	sshConfigInputs[0].Width = 50
 
@@ -138,9 +156,28 @@ func (m ConfigViewModel) Update(msg tea.Msg) (ConfigViewModel, tea.Cmd) {
 			case "tab", "shift+tab", "up", "down":
 				// Only one input for SSH Config, so no navigation needed
 				return m, nil
+			case "ctrl+g":
+				// Toggle resolving hosts through `ssh -G`
+				m.config.SSH.ResolveWithSSH = !m.config.SSH.ResolveWithSSH
+				if err := config.SaveConfig(m.config); err != nil {
+					m.errorMsg = fmt.Sprintf("Failed to save: %v", err)
+				}
+				return m, nil
 			case "enter":
-				// Save SSH Config
-				m.config.SSH.ConfigPath = strings.TrimSpace(m.sshConfigInputs[0].Value())
+				// Save SSH Config - the first path is the primary config, any
+				// further comma-separated paths are loaded as separate sources
+				var paths []string
+				for _, path := range strings.Split(m.sshConfigInputs[0].Value(), ",") {
+					paths = append(paths, strings.TrimSpace(path))
+				}
+				m.config.SSH.ConfigPath = paths[0]
+				m.config.SSH.ConfigPaths = nil
+				for _, path := range paths[1:] {
+					if path != "" {
+						m.config.SSH.ConfigPaths = append(m.config.SSH.ConfigPaths, path)
+					}
+				}
+				m.sources[1].Description = "Hosts from " + strings.Join(m.config.SSH.Paths(), ", ")
 
 				// Save to file
 				if err := config.SaveConfig(m.config); err != nil {
@@ -259,6 +296,26 @@ func (m ConfigViewModel) Update(msg tea.Msg) (ConfigViewModel, tea.Cmd) {
 				// Update description to show new theme
 				m.sources[m.focusIndex].Description = fmt.Sprintf("Current: %s", GetCurrentTheme().Name)
 
+				if err := config.SaveConfig(m.config); err != nil {
+					m.errorMsg = fmt.Sprintf("Failed to save: %v", err)
+					m.saved = false
+				} else {
+					m.saved = true
+					m.errorMsg = ""
+				}
+			} else if m.sources[m.focusIndex].Name == "Ping" {
+				// Cycle through ping methods
+				method := m.config.Ping.MethodFor(models.Host{})
+				nextIdx := 0
+				for i, name := range models.PingMethods {
+					if name == method {
+						nextIdx = (i + 1) % len(models.PingMethods)
+						break
+					}
+				}
+				m.config.Ping.Method = models.PingMethods[nextIdx]
+				m.sources[m.focusIndex].Description = pingMethodDescription(m.config.Ping)
+
 				if err := config.SaveConfig(m.config); err != nil {
 					m.errorMsg = fmt.Sprintf("Failed to save: %v", err)
 					m.saved = false
@@ -274,6 +331,8 @@ func (m ConfigViewModel) Update(msg tea.Msg) (ConfigViewModel, tea.Cmd) {
 				m.config.Sources.SSHBuddyEnabled = m.sources[0].Enabled
 				m.config.Sources.SSHConfigEnabled = m.sources[1].Enabled
 				m.config.Sources.TermixEnabled = m.sources[2].Enabled
+				m.config.KnownHosts.Enabled = m.sources[3].Enabled
+				m.config.Docker.Enabled = m.sources[4].Enabled
 
 				// Also update Termix enabled if it's the Termix source
 				if m.sources[m.focusIndex].Name == "Termix" {
@@ -290,7 +349,7 @@ func (m ConfigViewModel) Update(msg tea.Msg) (ConfigViewModel, tea.Cmd) {
 			}
 		case "e":
 			// Edit configuration for the selected source (not for Theme)
-			if m.sources[m.focusIndex].Configurable && m.sources[m.focusIndex].Name != "Theme" {
+			if m.sources[m.focusIndex].Name == "Termix" || m.sources[m.focusIndex].Name == "SSH Config" {
 				if m.sources[m.focusIndex].Name == "Termix" {
 					m.editingTermix = true
 					m.termixFocus = 0
@@ -432,8 +491,8 @@ func (m ConfigViewModel) View() string {
 func (m ConfigViewModel) renderSource(source SourceConfig, isSelected bool) string {
 	// Status indicator
 	var statusIcon string
-	if source.Name == "Theme" {
-		// Diamond icon with theme color for Theme option
+	if source.Name == "Theme" || source.Name == "Ping" {
+		// Diamond icon with theme color for settings that are cycled
 		statusIcon = lipgloss.NewStyle().Foreground(primaryColor).Render("◆")
 	} else if source.Enabled {
 		statusIcon = statusOnlineStyle.Render("✓")
@@ -463,7 +522,7 @@ func (m ConfigViewModel) renderSource(source SourceConfig, isSelected bool) stri
 			configIndicator = lipgloss.NewStyle().
 				Foreground(mutedColor).
 				Render(" (press 'e' to edit)")
-		} else if source.Name == "Theme" {
+		} else if source.Name == "Theme" || source.Name == "Ping" {
 			configIndicator = lipgloss.NewStyle().
 				Foreground(mutedColor).
 				Render(" (press space/enter to cycle)")
@@ -650,9 +709,22 @@ func (m ConfigViewModel) renderSSHConfigEdit() string {
 	header := lipgloss.JoinVertical(lipgloss.Left, asciiArt, subheading, separator)
 
 	// Form field
-	field := m.renderField("Config Path", m.sshConfigInputs[0], 0, "Path to SSH config file (leave empty for default ~/.ssh/config)")
+	field := m.renderField("Config Path", m.sshConfigInputs[0], 0, "Comma-separated SSH config files, each shown as its own source (empty = ~/.ssh/config)")
+
+	// Resolution mode
+	resolveState := lipgloss.NewStyle().Foreground(dimColor).Render("○ off")
+	if m.config.SSH.ResolveWithSSH {
+		resolveState = statusOnlineStyle.Render("✓ on")
+	}
+	resolveLine := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Foreground(textColor).Bold(true).Render("Resolve with ssh -G: ")+resolveState,
+		lipgloss.NewStyle().
+			Foreground(dimColor).
+			Italic(true).
+			Render("Ask OpenSSH for effective settings (Match, tokens); cached until the config changes"),
+	)
 
-	formContent := lipgloss.JoinVertical(lipgloss.Left, field)
+	formContent := lipgloss.JoinVertical(lipgloss.Left, field, resolveLine)
 
 	// Error message
 	var errorMsg string
@@ -664,6 +736,7 @@ func (m ConfigViewModel) renderSSHConfigEdit() string {
 
 	// Footer
 	keyBindings := []string{
+		keyStyle.Render("ctrl+g") + descStyle.Render(":toggle ssh -G "),
 		keyStyle.Render("enter") + descStyle.Render(":save "),
 		keyStyle.Render("esc") + descStyle.Render(":cancel"),
 	}
@@ -707,3 +780,17 @@ func (m ConfigViewModel) renderSSHConfigEdit() string {
 	// Center the box
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, mainBox)
 }
+
+// pingMethodDescription describes the global ping method and timeout
+func pingMethodDescription(cfg models.PingConfig) string {
+	var method string
+	switch cfg.MethodFor(models.Host{}) {
+	case models.PingMethodSSH:
+		method = "SSH banner check"
+	case models.PingMethodICMP:
+		method = "ICMP ping"
+	default:
+		method = "TCP connect to SSH port"
+	}
+	return fmt.Sprintf("Method: %s (timeout %dms, per-host override in the host form)", method, cfg.Timeout())
+}
@@ -12,19 +12,69 @@ import (
 
 // Lipgloss helper functions (no aliases needed, use lipgloss directly)
 
+// The form is laid out in pages of two columns with four fields each
+const (
+	formRowsPerColumn = 4
+	formFieldsPerPage = formRowsPerColumn * 2
+)
+
+// Input indexes
+const (
+	inputAlias = iota
+	inputHostname
+	inputUser
+	inputPort
+	inputIdentityFile
+	inputProxyJump
+	inputTags
+	inputDefaultPath
+	inputLocalForwards
+	inputRemoteForwards
+	inputDynamicForwards
+	inputForwardAgent
+	inputServerAlive
+	inputOptions
+	inputPingMethod
+	inputCount
+)
+
+// formFieldLabels are the labels for each input, in input order
+var formFieldLabels = []string{
+	"Alias",
+	"Hostname",
+	"User",
+	"Port",
+	"Identity File",
+	"Proxy Jump",
+	"Tags",
+	"Default Path",
+	"Local Forwards",
+	"Remote Forwards",
+	"Dynamic Forwards",
+	"Forward Agent",
+	"Keepalive (sec)",
+	"SSH Options",
+	"Ping Method",
+}
+
+// formPageTitles name each page of the form
+var formPageTitles = []string{"Connection", "Forwarding & Options"}
+
 type FormModel struct {
 	inputs         []textinput.Model
 	focused        int
 	err            error
 	host           *models.Host             // If editing, this is the host being edited
 	isEditing      bool                     // True if editing existing host
+	editTarget     string                   // Where edits are saved, if not sshbuddy's config
+	connectMode    string                   // Kept from the edited host, e.g. for Docker containers
 	validationErrs []models.ValidationError // Validation errors for current input
 	width          int
 	height         int
 }
 
 func NewFormModel() FormModel {
-	var inputs []textinput.Model = make([]textinput.Model, 8)
+	var inputs []textinput.Model = make([]textinput.Model, inputCount)
 
 	inputs[0] = textinput.New()
 	inputs[0].Placeholder = "Alias"
@@ -66,6 +116,41 @@ func NewFormModel() FormModel {
 	inputs[7].CharLimit = 100
 	inputs[7].Width = 30
 
+	inputs[inputLocalForwards] = textinput.New()
+	inputs[inputLocalForwards].Placeholder = "8080 localhost:80, ..."
+	inputs[inputLocalForwards].CharLimit = 200
+	inputs[inputLocalForwards].Width = 30
+
+	inputs[inputRemoteForwards] = textinput.New()
+	inputs[inputRemoteForwards].Placeholder = "9090 localhost:3000, ..."
+	inputs[inputRemoteForwards].CharLimit = 200
+	inputs[inputRemoteForwards].Width = 30
+
+	inputs[inputDynamicForwards] = textinput.New()
+	inputs[inputDynamicForwards].Placeholder = "1080, ..."
+	inputs[inputDynamicForwards].CharLimit = 100
+	inputs[inputDynamicForwards].Width = 30
+
+	inputs[inputForwardAgent] = textinput.New()
+	inputs[inputForwardAgent].Placeholder = "yes/no (no)"
+	inputs[inputForwardAgent].CharLimit = 3
+	inputs[inputForwardAgent].Width = 30
+
+	inputs[inputServerAlive] = textinput.New()
+	inputs[inputServerAlive].Placeholder = "ServerAliveInterval (optional)"
+	inputs[inputServerAlive].CharLimit = 5
+	inputs[inputServerAlive].Width = 30
+
+	inputs[inputOptions] = textinput.New()
+	inputs[inputOptions].Placeholder = "Key=Value; Key=Value"
+	inputs[inputOptions].CharLimit = 500
+	inputs[inputOptions].Width = 30
+
+	inputs[inputPingMethod] = textinput.New()
+	inputs[inputPingMethod].Placeholder = "tcp, ssh or icmp (default: global)"
+	inputs[inputPingMethod].CharLimit = 4
+	inputs[inputPingMethod].Width = 30
+
 	return FormModel{
 		inputs:  inputs,
 		focused: 0,
@@ -90,6 +175,23 @@ func NewFormModelWithHost(host models.Host) FormModel {
 	}
 	fm.inputs[7].SetValue(host.DefaultPath)
 
+	fm.inputs[inputLocalForwards].SetValue(strings.Join(host.LocalForwards, ", "))
+	fm.inputs[inputRemoteForwards].SetValue(strings.Join(host.RemoteForwards, ", "))
+	fm.inputs[inputDynamicForwards].SetValue(strings.Join(host.DynamicForwards, ", "))
+	if host.ForwardAgent {
+		fm.inputs[inputForwardAgent].SetValue("yes")
+	}
+	fm.inputs[inputServerAlive].SetValue(host.ServerAliveInterval)
+
+	// Options are separated by semicolons since values may contain commas
+	var options []string
+	for _, key := range host.SortedOptionKeys() {
+		options = append(options, key+"="+host.Options[key])
+	}
+	fm.inputs[inputOptions].SetValue(strings.Join(options, "; "))
+	fm.inputs[inputPingMethod].SetValue(host.PingMethod)
+	fm.connectMode = host.ConnectMode
+
 	return fm
 }
 
@@ -108,18 +210,12 @@ func (m FormModel) Update(msg tea.Msg) (FormModel, tea.Cmd) {
 		m.height = msg.Height
 	case tea.KeyMsg:
 		switch msg.Type {
+		case tea.KeyCtrlS:
+			// Save from any field
+			return m.submit()
 		case tea.KeyTab, tea.KeyDown, tea.KeyEnter:
 			if msg.Type == tea.KeyEnter && m.focused == len(m.inputs)-1 {
-				// Validate before submitting
-				host := m.GetHost()
-				validationErrs := host.Validate()
-				if len(validationErrs) > 0 {
-					m.validationErrs = validationErrs
-					return m, nil
-				}
-				// Submit
-				m.validationErrs = nil
-				return m, func() tea.Msg { return FormSubmittedMsg{host} }
+				return m.submit()
 			}
 			m.focused++
 			if m.focused >= len(m.inputs) {
@@ -132,18 +228,28 @@ func (m FormModel) Update(msg tea.Msg) (FormModel, tea.Cmd) {
 			}
 		case tea.KeyRight:
 			// Move to corresponding field in right column (add 4 if in left column)
-			if m.focused < 4 {
+			if m.focused%formFieldsPerPage < formRowsPerColumn {
 				// In left column, move to right column
-				newFocus := m.focused + 4
+				newFocus := m.focused + formRowsPerColumn
 				if newFocus < len(m.inputs) {
 					m.focused = newFocus
 				}
 			}
 		case tea.KeyLeft:
 			// Move to corresponding field in left column (subtract 4 if in right column)
-			if m.focused >= 4 {
+			if m.focused%formFieldsPerPage >= formRowsPerColumn {
 				// In right column, move to left column
-				m.focused = m.focused - 4
+				m.focused = m.focused - formRowsPerColumn
+			}
+		case tea.KeyPgDown:
+			// Jump to the first field of the next page
+			if next := (m.focused/formFieldsPerPage + 1) * formFieldsPerPage; next < len(m.inputs) {
+				m.focused = next
+			}
+		case tea.KeyPgUp:
+			// Jump to the first field of the previous page
+			if page := m.focused / formFieldsPerPage; page > 0 {
+				m.focused = (page - 1) * formFieldsPerPage
 			}
 		}
 	}
@@ -159,6 +265,35 @@ func (m FormModel) Update(msg tea.Msg) (FormModel, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// submit validates the form and emits FormSubmittedMsg when it is valid
+func (m FormModel) submit() (FormModel, tea.Cmd) {
+	// Validate before submitting
+	host := m.GetHost()
+	validationErrs := host.Validate()
+	if m.editTarget != "" {
+		// SSH config hosts may leave the user to ssh
+		validationErrs = withoutField(validationErrs, "User")
+	}
+	if len(validationErrs) > 0 {
+		m.validationErrs = validationErrs
+		return m, nil
+	}
+	// Submit
+	m.validationErrs = nil
+	return m, func() tea.Msg { return FormSubmittedMsg{host} }
+}
+
+// withoutField drops the validation errors for field
+func withoutField(errs []models.ValidationError, field string) []models.ValidationError {
+	var kept []models.ValidationError
+	for _, err := range errs {
+		if err.Field != field {
+			kept = append(kept, err)
+		}
+	}
+	return kept
+}
+
 func (m FormModel) View() string {
 	const boxWidth = 80
 
@@ -176,7 +311,13 @@ func (m FormModel) View() string {
 	subheadingText := "Add New Host"
 	if m.isEditing {
 		subheadingText = "Edit Host"
+		if m.editTarget != "" {
+			subheadingText = "Edit Host in " + m.editTarget
+		}
 	}
+	page := m.focused / formFieldsPerPage
+	pageCount := (len(m.inputs) + formFieldsPerPage - 1) / formFieldsPerPage
+	subheadingText = fmt.Sprintf("%s · %s (%d/%d)", subheadingText, formPageTitles[page], page+1, pageCount)
 	subheading := lipgloss.NewStyle().
 		Foreground(dimColor).
 		Width(boxWidth - 4).
@@ -192,25 +333,17 @@ func (m FormModel) View() string {
 	header := lipgloss.JoinVertical(lipgloss.Left, asciiArt, subheading, separator)
 
 	// Form fields - 2-column layout
-	fields := []struct {
+	type formField struct {
 		label string
 		input textinput.Model
-	}{
-		{"Alias", m.inputs[0]},
-		{"Hostname", m.inputs[1]},
-		{"User", m.inputs[2]},
-		{"Port", m.inputs[3]},
-		{"Identity File", m.inputs[4]},
-		{"Proxy Jump", m.inputs[5]},
-		{"Tags", m.inputs[6]},
-		{"Default Path", m.inputs[7]},
+	}
+	var fields []formField
+	for i, input := range m.inputs {
+		fields = append(fields, formField{formFieldLabels[i], input})
 	}
 
 	// Render each field
-	renderField := func(i int, field struct {
-		label string
-		input textinput.Model
-	}) string {
+	renderField := func(i int, field formField) string {
 		isFocused := i == m.focused
 
 		// Label
@@ -229,21 +362,24 @@ func (m FormModel) View() string {
 		)
 	}
 
-	// Split into two columns (first 4 fields in left, last 3 in right)
+	// Split the current page into two columns (first 4 fields left, rest right)
 	const columnWidth = 35
 
 	var leftColumn []string
 	var rightColumn []string
 
-	// Left column: Alias, Hostname, User, Port
-	for i := 0; i < 4 && i < len(fields); i++ {
+	pageStart := page * formFieldsPerPage
+	pageEnd := min(pageStart+formFieldsPerPage, len(fields))
+
+	// Left column: Alias, Hostname, User, Port on the first page
+	for i := pageStart; i < pageStart+formRowsPerColumn && i < pageEnd; i++ {
 		fieldView := renderField(i, fields[i])
 		leftColumn = append(leftColumn, lipgloss.NewStyle().Width(columnWidth).Render(fieldView))
 		leftColumn = append(leftColumn, "") // spacing
 	}
 
-	// Right column: Identity File, Proxy Jump, Tags, Default Path
-	for i := 4; i < len(fields); i++ {
+	// Right column: Identity File, Proxy Jump, Tags, Default Path on the first page
+	for i := pageStart + formRowsPerColumn; i < pageEnd; i++ {
 		fieldView := renderField(i, fields[i])
 		rightColumn = append(rightColumn, lipgloss.NewStyle().Width(columnWidth).Render(fieldView))
 		rightColumn = append(rightColumn, "") // spacing
@@ -276,7 +412,8 @@ func (m FormModel) View() string {
 	keyBindings := []string{
 		keyStyle.Render("↑↓/tab") + descStyle.Render(":navigate "),
 		keyStyle.Render("←→") + descStyle.Render(":columns "),
-		keyStyle.Render("enter") + descStyle.Render(":save "),
+		keyStyle.Render("pgup/pgdn") + descStyle.Render(":page "),
+		keyStyle.Render("ctrl+s") + descStyle.Render(":save "),
 		keyStyle.Render("esc") + descStyle.Render(":cancel"),
 	}
 	footer := lipgloss.NewStyle().
@@ -334,15 +471,64 @@ func (m FormModel) GetHost() models.Host {
 		}
 	}
 
+	forwardAgent := strings.ToLower(strings.TrimSpace(m.inputs[inputForwardAgent].Value()))
+
 	return models.Host{
-		Alias:        m.inputs[0].Value(),
-		Hostname:     m.inputs[1].Value(),
-		User:         m.inputs[2].Value(),
-		Port:         m.inputs[3].Value(),
-		IdentityFile: strings.TrimSpace(m.inputs[4].Value()),
-		ProxyJump:    strings.TrimSpace(m.inputs[5].Value()),
-		Tags:         tags,
-		DefaultPath:  strings.TrimSpace(m.inputs[7].Value()),
-		Source:       "manual",
+		Alias:               m.inputs[0].Value(),
+		Hostname:            m.inputs[1].Value(),
+		User:                m.inputs[2].Value(),
+		Port:                m.inputs[3].Value(),
+		IdentityFile:        strings.TrimSpace(m.inputs[4].Value()),
+		ProxyJump:           strings.TrimSpace(m.inputs[5].Value()),
+		Tags:                tags,
+		DefaultPath:         strings.TrimSpace(m.inputs[7].Value()),
+		Source:              "manual",
+		LocalForwards:       splitList(m.inputs[inputLocalForwards].Value()),
+		RemoteForwards:      splitList(m.inputs[inputRemoteForwards].Value()),
+		DynamicForwards:     splitList(m.inputs[inputDynamicForwards].Value()),
+		ForwardAgent:        forwardAgent == "yes" || forwardAgent == "y" || forwardAgent == "true",
+		ServerAliveInterval: strings.TrimSpace(m.inputs[inputServerAlive].Value()),
+		Options:             parseOptions(m.inputs[inputOptions].Value()),
+		PingMethod:          strings.ToLower(strings.TrimSpace(m.inputs[inputPingMethod].Value())),
+		ConnectMode:         m.connectMode,
+	}
+}
+
+// parseOptions parses "Key=Value; Key Value" input into an options map.
+// Known keywords are stored with their canonical spelling; unknown ones are
+// kept as typed so validation can report them.
+func parseOptions(value string) map[string]string {
+	var options map[string]string
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, optionValue, found := strings.Cut(part, "=")
+		if !found {
+			key, optionValue, _ = strings.Cut(part, " ")
+		}
+		key = strings.TrimSpace(key)
+		if canonical, ok := models.CanonicalSSHOption(key); ok {
+			key = canonical
+		}
+
+		if options == nil {
+			options = make(map[string]string)
+		}
+		options[key] = strings.TrimSpace(optionValue)
+	}
+	return options
+}
+
+// splitList splits a comma-separated input into trimmed, non-empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			items = append(items, trimmed)
+		}
 	}
+	return items
 }
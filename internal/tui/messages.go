@@ -1,6 +1,12 @@
 package tui
 
-import "sshbuddy/pkg/models"
+import (
+	"sshbuddy/internal/config"
+	"sshbuddy/pkg/models"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 // ConnectMsg is a Bubble Tea message for SSH connection
 type ConnectMsg struct {
@@ -9,9 +15,38 @@ type ConnectMsg struct {
 
 // PingResultMsg is a Bubble Tea message for ping results
 type PingResultMsg struct {
-	Host     models.Host
-	Status   bool   // true if reachable
-	PingTime string // ping time in ms
+	Host        models.Host
+	Status      bool          // true if reachable
+	PingTime    string        // ping time in ms
+	Latency     time.Duration // Measured latency, recorded in the ping history
+	Via         string        // Jump host the status was checked through
+	BastionDown bool          // The jump host did not answer
+	Generation  int           // Ping round the result belongs to (0 for single pings)
+	next        tea.Cmd
+}
+
+// PingAllMsg starts a new round of pinging all hosts
+type PingAllMsg struct{}
+
+// PingRoundDoneMsg is sent when every host of a ping round has been probed
+type PingRoundDoneMsg struct {
+	Generation int
+}
+
+// pingRefreshMsg triggers a periodic re-check of all hosts
+type pingRefreshMsg struct {
+	Generation int
+}
+
+// SourceLoadedMsg is sent when a host source has loaded in the background
+type SourceLoadedMsg struct {
+	Generation int // Host load the result belongs to; results of older loads are dropped
+	Result     config.SourceResult
+}
+
+// sourceWatchMsg triggers a check of the files of watched sources
+type sourceWatchMsg struct {
+	Generation int
 }
 
 // FormSubmittedMsg is sent when a form is submitted
@@ -1,12 +1,18 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sshbuddy/internal/config"
+	"sshbuddy/internal/ssh"
+	"sshbuddy/internal/termix"
 	"sshbuddy/pkg/models"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -24,35 +30,80 @@ const (
 )
 
 type item struct {
-	host     models.Host
-	status   string // Ping status indicator
-	pinging  bool   // Is currently being pinged
-	pingTime string // Ping time in ms
+	host        models.Host
+	status      string           // Ping status indicator
+	pinging     bool             // Is currently being pinged
+	pingTime    string           // Ping time in ms
+	via         string           // Jump host the status was checked through
+	bastionDown bool             // The jump host did not answer
+	history     []ssh.PingSample // Recent probe results, oldest first
 }
 
-func (i item) Title() string {
-	// Colored dot based on ping status
-	var statusText string
+// statusIndicator renders the colored status dot for the item. Hosts checked
+// through a jump host use a ringed dot, and a hollow red one when the jump
+// host itself is down (the target's state is then unknown).
+func (i item) statusIndicator() string {
 	if i.pinging {
 		// Yellow dot for pinging in progress
-		statusText = statusPingingStyle.Render("●")
-	} else {
-		switch i.status {
-		case "🟢":
-			statusText = statusOnlineStyle.Render("●")
-		case "🔴":
-			statusText = statusOfflineStyle.Render("●")
-		default:
-			statusText = statusUnknownStyle.Render("○")
-		}
+		return statusPingingStyle.Render("●")
+	}
+	if i.bastionDown {
+		return statusOfflineStyle.Render("◌")
+	}
+
+	dot := "●"
+	if i.via != "" {
+		dot = "◉"
+	}
+	switch i.status {
+	case "🟢":
+		return statusOnlineStyle.Render(dot)
+	case "🔴":
+		return statusOfflineStyle.Render(dot)
+	default:
+		return statusUnknownStyle.Render("○")
+	}
+}
+
+// statusNote returns the text shown in parentheses after the alias: the ping
+// time and, for hosts behind a jump host, how they were reached
+func (i item) statusNote() string {
+	if i.pinging {
+		return ""
+	}
+	if i.bastionDown {
+		return "bastion down"
+	}
+	if i.via != "" && i.status == "🟢" {
+		return strings.TrimSpace(i.pingTime + " via bastion")
+	}
+	return i.pingTime
+}
+
+// historySummary renders a sparkline of the last n probes followed by the
+// packet loss over the whole window, or "" before the first probe
+func (i item) historySummary(n int) string {
+	if len(i.history) == 0 {
+		return ""
 	}
+	samples := i.history
+	if len(samples) > n {
+		samples = samples[len(samples)-n:]
+	}
+	return fmt.Sprintf("%s %.0f%%", ssh.Sparkline(samples), ssh.Loss(i.history))
+}
+
+func (i item) Title() string {
+	title := fmt.Sprintf("%s %s", i.statusIndicator(), i.host.Alias)
 
 	// Add ping time if available
-	if i.pingTime != "" {
-		return fmt.Sprintf("%s %s %s", statusText, i.host.Alias,
-			lipgloss.NewStyle().Foreground(dimColor).Render(fmt.Sprintf("(%s)", i.pingTime)))
+	if note := i.statusNote(); note != "" {
+		title += " " + lipgloss.NewStyle().Foreground(dimColor).Render(fmt.Sprintf("(%s)", note))
+	}
+	if summary := i.historySummary(ssh.PingHistorySize); summary != "" {
+		title += " " + lipgloss.NewStyle().Foreground(dimColor).Render(summary)
 	}
-	return fmt.Sprintf("%s %s", statusText, i.host.Alias)
+	return title
 }
 
 func (i item) Description() string {
@@ -60,7 +111,7 @@ func (i item) Description() string {
 	if port == "" {
 		port = "22"
 	}
-	return fmt.Sprintf("%s@%s:%s", i.host.User, i.host.Hostname, port)
+	return fmt.Sprintf("%s:%s", i.host.Destination(), port)
 }
 
 func (i item) FilterValue() string { return i.host.Alias + i.host.Hostname }
@@ -72,13 +123,23 @@ type Model struct {
 	termixAuth         TermixAuthModel
 	state              sessionState
 	config             *models.Config
-	pingStatus         map[string]bool   // track ping status for each host
-	pinging            map[string]bool   // track which hosts are currently being pinged
-	pingTimes          map[string]string // track ping times for each host
+	pingStatus         map[string]bool    // track ping status for each host
+	pinging            map[string]bool    // track which hosts are currently being pinged
+	pingTimes          map[string]string  // track ping times for each host
+	pingVia            map[string]string  // track the jump host each host was checked through
+	bastionDown        map[string]bool    // track hosts whose jump host is unreachable
+	pingCancel         context.CancelFunc // Cancels the running ping round
+	pingGeneration     int                // Current ping round; results of older rounds are dropped
+	pingHistory        ssh.PingHistory    // Recent probe results per host alias
+	sources            []*sourceState     // Enabled host sources in precedence order
+	sourceGeneration   int                // Current host load; results of older loads are dropped
+	spinner            spinner.Model      // Shown next to sources still loading
 	width              int
 	height             int
 	selectedHost       *models.Host             // Host to connect to after quitting
-	editingIndex       int                      // Index of host being edited (-1 if adding new)
+	editingAlias       string                   // Alias of the host being edited ("" if adding new)
+	editingSource      string                   // SSH config source being edited ("" for manual hosts)
+	editingOriginal    *models.Host             // Host as loaded from editingSource, to diff edits against
 	deleteConfirmHost  *models.Host             // Host pending deletion confirmation
 	deleteConfirmIdx   int                      // Index of host pending deletion
 	configErrors       []models.ValidationError // Config validation errors
@@ -86,27 +147,24 @@ type Model struct {
 	selectedSourceIdx  int                      // Selected source index in source selection dialog
 }
 
+// NewModel creates the TUI model with the manual hosts loaded. The other
+// sources are loaded in the background once the program starts (see Init).
 func NewModel() Model {
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadConfigRaw()
 	var validationErrors []models.ValidationError
-	var needsTermixAuth bool
+	loadSources := true
 
 	if err != nil {
-		// Check if this is a Termix auth error
-		if strings.Contains(err.Error(), "authentication required") {
-			needsTermixAuth = true
-			cfg = &models.Config{Hosts: []models.Host{}}
-		} else {
-			// Convert error to validation error for display
-			validationErrors = []models.ValidationError{
-				{
-					Field:   "Config",
-					Message: err.Error(),
-					Index:   -1,
-				},
-			}
-			cfg = &models.Config{Hosts: []models.Host{}}
+		// Convert error to validation error for display
+		validationErrors = []models.ValidationError{
+			{
+				Field:   "Config",
+				Message: err.Error(),
+				Index:   -1,
+			},
 		}
+		cfg = &models.Config{Hosts: []models.Host{}}
+		loadSources = false
 	} else {
 		// Validate config
 		validationErrors = cfg.Validate()
@@ -119,11 +177,6 @@ func NewModel() Model {
 	}
 	ApplyTheme(themeName)
 
-	items := []list.Item{}
-	for _, h := range cfg.Hosts {
-		items = append(items, item{host: h, status: "⚪"})
-	}
-
 	// Custom delegate with original styling
 	delegate := list.NewDefaultDelegate()
 	delegate.SetHeight(3) // Three lines per item (title + description + tags)
@@ -152,7 +205,7 @@ func NewModel() Model {
 		Foreground(dimColor).
 		Padding(0, 0, 0, 2)
 
-	l := list.New(items, delegate, 0, 0)
+	l := list.New([]list.Item{}, delegate, 0, 0)
 	l.Title = ""
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
@@ -169,15 +222,22 @@ func NewModel() Model {
 		pingStatus:   make(map[string]bool),
 		pinging:      make(map[string]bool),
 		pingTimes:    make(map[string]string),
-		editingIndex: -1,
+		pingVia:      make(map[string]string),
+		bastionDown:  make(map[string]bool),
+		pingHistory:  config.LoadPingHistory(),
+		spinner:      newSourceSpinner(),
 		configErrors: validationErrors,
 	}
 
-	// If Termix auth is needed, show auth form
-	if needsTermixAuth {
-		m.state = stateTermixAuth
-	} else if len(validationErrors) > 0 {
-		// If there are validation errors, show error state
+	if loadSources {
+		// The background loads are started by Init
+		m.loadHosts(cfg)
+	} else {
+		m.config = cfg
+	}
+
+	// If there are validation errors, show error state
+	if len(validationErrors) > 0 {
 		m.state = stateConfigError
 	}
 
@@ -185,12 +245,44 @@ func NewModel() Model {
 }
 
 func (m Model) Init() tea.Cmd {
-	// Mark all hosts as pinging on startup
+	// Ping the hosts loaded so far and load the other sources
+	return tea.Batch(
+		func() tea.Msg { return PingAllMsg{} },
+		m.pendingSourceLoads(),
+		m.watchSources(),
+	)
+}
+
+// startPingRound cancels any running ping round and starts a new one over all
+// hosts. markPinging shows the in-progress indicator on every host; otherwise
+// (periodic refreshes, newly loaded sources) only hosts that haven't been
+// checked yet get it, so the list doesn't flicker.
+func (m *Model) startPingRound(markPinging bool) tea.Cmd {
+	m.stopPinging()
+	m.pingGeneration++
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.pingCancel = cancel
+
 	for _, h := range m.config.Hosts {
 		key := GetHostKey(h)
-		m.pinging[key] = true
+		if _, checked := m.pingStatus[key]; markPinging || !checked {
+			m.pinging[key] = true
+		}
 	}
-	return StartPingAll(m.config.Hosts)
+	m.refreshList()
+	return StartPingAll(ctx, m.pingGeneration, m.config.Hosts, pingOptions(m.config))
+}
+
+// stopPinging cancels the running ping round, if any, and saves the ping
+// history collected so far
+func (m *Model) stopPinging() {
+	if m.pingCancel != nil {
+		m.pingCancel()
+		m.pingCancel = nil
+	}
+	// Best effort: a lost history only costs the sparklines
+	config.SavePingHistory(m.pingHistory)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -200,6 +292,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
+			m.stopPinging()
 			return m, tea.Quit
 		}
 
@@ -213,7 +306,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Connect to selected host
 				if selectedItem, ok := m.list.SelectedItem().(item); ok {
 					// Check if host is available in multiple sources
-					if len(selectedItem.host.AvailableIn) > 1 {
+					if len(selectableSources(selectedItem.host)) > 1 {
 						// Show source selection dialog
 						m.pendingConnectHost = &selectedItem.host
 						m.selectedSourceIdx = 0
@@ -242,16 +335,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.form = NewFormModel() // Reset form
 					m.form.width = m.width
 					m.form.height = m.height
-					m.editingIndex = -1 // -1 means adding new
+					m.editingAlias = "" // No alias means adding new
+					m.editingSource = ""
 					return m, m.form.Init()
 				case "p":
-					// Ping all servers - mark all as pinging
-					for _, h := range m.config.Hosts {
-						key := GetHostKey(h)
-						m.pinging[key] = true
-					}
-					m.refreshList()
-					return m, StartPingAll(m.config.Hosts)
+					// Ping all servers, restarting any round in progress
+					return m, m.startPingRound(true)
 				case "up", "k":
 					// Move up in 2-column layout (go back 2 items)
 					currentIdx := m.list.Index()
@@ -283,25 +372,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					return m, nil
 				case "e":
-					// Edit selected host (only if sshbuddy is one of the available sources)
+					// Edit selected host. Hosts with an sshbuddy source are edited in
+					// sshbuddy's config; hosts only defined in SSH config files are
+					// written back to the file they came from.
 					if selectedItem, ok := m.list.SelectedItem().(item); ok {
-						// Check if manual/sshbuddy is in AvailableIn
 						hasManualSource := false
+						sshConfigSource := ""
 						for _, src := range selectedItem.host.AvailableIn {
 							if src == "manual" || src == "sshbuddy" {
 								hasManualSource = true
 								break
 							}
+							if sshConfigSource == "" && models.IsSSHConfigSource(src) {
+								sshConfigSource = src
+							}
 						}
-						if !hasManualSource {
-							// Cannot edit - no manual source available
-							return m, nil
+
+						hostToEdit := selectedItem.host
+						m.editingSource = ""
+						m.editingOriginal = nil
+						if hasManualSource {
+							// Edit the manual version, not one merged from other sources
+							if variant, ok := selectedItem.host.Variants["manual"]; ok && variant != nil {
+								hostToEdit = *variant
+							}
+						} else {
+							if sshConfigSource == "" {
+								// Cannot edit - no editable source available
+								return m, nil
+							}
+							if variant, ok := selectedItem.host.Variants[sshConfigSource]; ok && variant != nil {
+								hostToEdit = *variant
+							}
+							original := hostToEdit
+							m.editingSource = sshConfigSource
+							m.editingOriginal = &original
 						}
+
 						m.state = stateForm
-						m.form = NewFormModelWithHost(selectedItem.host)
+						m.form = NewFormModelWithHost(hostToEdit)
+						if m.editingSource != "" {
+							m.form.editTarget = sourceLongName(m.editingSource)
+						}
 						m.form.width = m.width
 						m.form.height = m.height
-						m.editingIndex = m.list.Index()
+						// Hosts may be re-merged and re-sorted while the form is
+						// open, so the host is looked up by alias on submit
+						m.editingAlias = selectedItem.host.Alias
 						return m, m.form.Init()
 					}
 				case "c":
@@ -317,7 +434,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.form = NewFormModelWithHost(duplicatedHost)
 						m.form.width = m.width
 						m.form.height = m.height
-						m.editingIndex = -1 // -1 means adding new (not editing)
+						m.editingAlias = "" // Adding new (not editing)
+						m.editingSource = ""
 						return m, m.form.Init()
 					}
 				case "d", "delete":
@@ -350,6 +468,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							return ToggleFavoriteMsg{}
 						}
 					}
+				case "a":
+					// Add a host discovered in known_hosts to the manual hosts
+					if selectedItem, ok := m.list.SelectedItem().(item); ok && isDiscovered(selectedItem.host) {
+						return m, m.promoteHost(selectedItem.host)
+					}
+					return m, nil
 				}
 			}
 		} else if m.state == stateForm {
@@ -360,13 +484,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else if m.state == stateConfig {
 			if msg.String() == "esc" {
 				// Reload config in case it was changed
-				cfg, err := config.LoadConfig()
-				if err == nil {
-					m.config = cfg
-					m.refreshList()
-				}
+				reload := m.reloadHosts()
 				m.state = stateList
-				return m, nil
+				// Hosts or ping settings may have changed
+				return m, tea.Batch(reload, m.startPingRound(true))
 			}
 		} else if m.state == stateTermixAuth {
 			if msg.String() == "esc" {
@@ -395,12 +516,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						// Save the updated manual hosts
 						config.SaveConfig(rawConfig)
 
-						// Reload full config to re-aggregate from all sources
-						newConfig, err := config.LoadConfig()
-						if err == nil {
-							m.config = newConfig
-						}
-						m.refreshList()
+						// Reload to re-aggregate from all sources
+						cmd = m.reloadHosts()
 
 						// Adjust selection if needed
 						if m.deleteConfirmIdx >= len(m.config.Hosts) && len(m.config.Hosts) > 0 {
@@ -410,7 +527,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.deleteConfirmHost = nil
 				m.state = stateList
-				return m, nil
+				return m, cmd
 			case "n", "N", "esc":
 				// Cancel deletion
 				m.deleteConfirmHost = nil
@@ -429,6 +546,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateList
 				return m, nil
 			case "q", "Q":
+				m.stopPinging()
 				return m, tea.Quit
 			}
 		} else if m.state == stateSourceSelect {
@@ -441,21 +559,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case "down", "j":
 				// Move down in source list
-				if m.pendingConnectHost != nil && m.selectedSourceIdx < len(m.pendingConnectHost.AvailableIn)-1 {
+				if m.pendingConnectHost != nil && m.selectedSourceIdx < len(selectableSources(*m.pendingConnectHost))-1 {
 					m.selectedSourceIdx++
 				}
 				return m, nil
 			case "enter":
 				// Connect with selected source
 				if m.pendingConnectHost != nil {
-					selectedSource := m.pendingConnectHost.AvailableIn[m.selectedSourceIdx]
+					selectedSource := selectableSources(*m.pendingConnectHost)[m.selectedSourceIdx]
 
 					// Prefer using the stored variant for the selected source if available
 					var hostToConnect models.Host
 					if variant, ok := m.pendingConnectHost.Variants[selectedSource]; ok && variant != nil {
 						hostToConnect = *variant
 						// Ensure source field is correct (variants store original source)
-						hostToConnect.Source = selectedSource
+						if selectedSource != models.MergedSource {
+							hostToConnect.Source = selectedSource
+						}
 					} else {
 						// Fallback: Create a copy of the host with the selected source label
 						hostCopy := *m.pendingConnectHost
@@ -500,81 +620,163 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.termixAuth.width = msg.Width
 		m.termixAuth.height = msg.Height
 
+	case PingAllMsg:
+		return m, m.startPingRound(true)
+
+	case PingRoundDoneMsg:
+		if msg.Generation != m.pingGeneration {
+			return m, nil
+		}
+		m.stopPinging()
+
+		// Schedule the next periodic refresh, if enabled
+		if m.config.Ping.RefreshSeconds > 0 {
+			generation := m.pingGeneration
+			return m, tea.Tick(time.Duration(m.config.Ping.RefreshSeconds)*time.Second, func(time.Time) tea.Msg {
+				return pingRefreshMsg{Generation: generation}
+			})
+		}
+		return m, nil
+
+	case pingRefreshMsg:
+		// Skip refreshes scheduled before the last manual ping or reload
+		if msg.Generation != m.pingGeneration {
+			return m, nil
+		}
+		return m, m.startPingRound(false)
+
 	case PingResultMsg:
+		// Drop results from rounds that were cancelled
+		if msg.Generation != 0 && msg.Generation != m.pingGeneration {
+			return m, nil
+		}
+
 		// Update ping status, time, and clear pinging state
 		key := GetHostKey(msg.Host)
 		m.pingStatus[key] = msg.Status
 		m.pingTimes[key] = msg.PingTime
+		m.pingVia[key] = msg.Via
+		m.bastionDown[key] = msg.BastionDown
 		m.pinging[key] = false
+
+		// A down bastion says nothing about the host itself, so it isn't recorded
+		if !msg.BastionDown {
+			m.pingHistory.Record(msg.Host.Alias, msg.Status, msg.Latency)
+		}
 		m.refreshList()
-		return m, nil
+		return m, msg.next
 
 	case FormSubmittedMsg:
+		if m.editingSource != "" && m.editingOriginal != nil {
+			// Write the edit back to the SSH config file the host came from
+			if err := config.UpdateSSHConfigHost(m.config, m.editingSource, *m.editingOriginal, msg.Host); err != nil {
+				m.form.validationErrs = []models.ValidationError{
+					{
+						Field:   "SSH Config",
+						Message: err.Error(),
+						Index:   -1,
+					},
+				}
+				return m, nil
+			}
+
+			// Reload to pick up the rewritten file
+			reload := m.reloadHosts()
+			m.state = stateList
+			m.editingAlias = ""
+			m.editingSource = ""
+			m.editingOriginal = nil
+			return m, tea.Batch(reload, PingHost(msg.Host, pingOptions(m.config)))
+		}
+
 		// Load raw config to update manual hosts
 		rawConfig, err := config.LoadConfigRaw()
 		if err == nil {
-			if m.editingIndex >= 0 {
-				// Editing existing host - find and update by alias
-				alias := m.config.Hosts[m.editingIndex].Alias
+			// Editing existing host - find and update by alias
+			found := false
+			if m.editingAlias != "" {
 				for i, h := range rawConfig.Hosts {
-					if h.Alias == alias {
+					if h.Alias == m.editingAlias {
 						rawConfig.Hosts[i] = msg.Host
+						found = true
 						break
 					}
 				}
-			} else {
-				// Adding new host
+			}
+			if !found {
+				// Adding new host, or one removed from the config meanwhile
 				rawConfig.Hosts = append(rawConfig.Hosts, msg.Host)
 			}
 
 			// Save the updated manual hosts
 			config.SaveConfig(rawConfig)
 
-			// Reload full config to re-aggregate from all sources
-			newConfig, err := config.LoadConfig()
-			if err == nil {
-				m.config = newConfig
-			}
+			// Reload to re-aggregate from all sources
+			cmd = m.reloadHosts()
 		}
 		m.state = stateList
-		m.editingIndex = -1
-		m.refreshList()
+		m.editingAlias = ""
 		// Ping the host
-		return m, PingHost(msg.Host)
+		return m, tea.Batch(cmd, PingHost(msg.Host, pingOptions(m.config)))
 
 	case ConnectMsg:
 		// Store the host and quit the TUI
 		m.selectedHost = &msg.Host
+		m.stopPinging()
 		return m, tea.Quit
 
 	case TermixAuthSuccessMsg:
-		// Reload config after successful auth
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			// If still auth error, stay in auth state
-			if strings.Contains(err.Error(), "authentication required") {
-				return m, nil
-			}
-			// Other errors - show error state
-			m.configErrors = []models.ValidationError{
-				{
-					Field:   "Config",
-					Message: err.Error(),
-					Index:   -1,
-				},
+		// Reload with the new token; the Termix hosts are pinged once loaded
+		m.state = stateList
+		return m, m.reloadHosts()
+
+	case SourceLoadedMsg:
+		// Drop results of loads that were superseded by a reload
+		if msg.Generation != m.sourceGeneration {
+			return m, nil
+		}
+		var state *sourceState
+		for _, s := range m.sources {
+			if s.source.Name() == msg.Result.Name {
+				state = s
 			}
-			m.state = stateConfigError
+		}
+		if state == nil {
 			return m, nil
 		}
-		m.config = cfg
-		m.refreshList()
-		m.state = stateList
-		// Start pinging all hosts
-		for _, h := range m.config.Hosts {
-			key := GetHostKey(h)
-			m.pinging[key] = true
+		state.loading = false
+		state.result, state.loaded = msg.Result, true
+		m.applySources()
+
+		var authErr *termix.AuthError
+		if errors.As(msg.Result.Err, &authErr) && m.state == stateList {
+			// Ask for credentials; cached hosts, if any, are listed meanwhile
+			m.state = stateTermixAuth
+			m.termixAuth = NewTermixAuthModel()
+			m.termixAuth.width = m.width
+			m.termixAuth.height = m.height
+			cmds = append(cmds, m.termixAuth.Init())
 		}
-		return m, StartPingAll(m.config.Hosts)
+		if len(msg.Result.Hosts) > 0 {
+			// Check the new hosts; hosts already checked keep their status meanwhile
+			cmds = append(cmds, m.startPingRound(false))
+		}
+		return m, tea.Batch(cmds...)
+
+	case sourceWatchMsg:
+		// Drop checks scheduled before a reload, which schedules its own
+		if msg.Generation != m.sourceGeneration {
+			return m, nil
+		}
+		return m, tea.Batch(m.reloadChangedSources(), m.watchSources())
+
+	case spinner.TickMsg:
+		// Let the spinner stop once every source has loaded
+		if !m.sourceLoading() {
+			return m, nil
+		}
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
 
 	case ToggleFavoriteMsg:
 		// Toggle favorite status for selected host
@@ -594,22 +796,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				delete(m.config.Favorites, alias)
 			}
 
-			// Save config
-			config.SaveConfig(m.config)
-
-			// Reload config to re-sort hosts
-			cfg, err := config.LoadConfig()
-			if err == nil {
-				m.config = cfg
-				// Find the host we just toggled and select it
-				for i, h := range m.config.Hosts {
-					if h.Alias == alias {
-						m.list.Select(i)
-						break
-					}
-				}
-			}
-			m.refreshList()
+			// Save only the favorite; the config on disk may have changed
+			// since it was loaded, e.g. by a refreshed Termix token
+			config.SaveFavorite(alias, m.config.Hosts[currentIdx].Favorite)
+
+			// Re-merge the loaded sources to re-sort hosts; the selection
+			// follows the host we just toggled
+			m.applySources()
 		}
 		return m, nil
 	}
@@ -634,7 +827,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View is implemented in view.go
 
+// refreshList rebuilds the list items from the hosts, keeping the cursor on
+// the selected host if it is still listed
 func (m *Model) refreshList() {
+	selectedAlias := ""
+	if selected, ok := m.list.SelectedItem().(item); ok {
+		selectedAlias = selected.host.Alias
+	}
+
 	items := []list.Item{}
 	for _, h := range m.config.Hosts {
 		key := GetHostKey(h)
@@ -644,9 +844,27 @@ func (m *Model) refreshList() {
 		}
 		isPinging := m.pinging[key]
 		pingTime := m.pingTimes[key]
-		items = append(items, item{host: h, status: status, pinging: isPinging, pingTime: pingTime})
+		items = append(items, item{
+			host:        h,
+			status:      status,
+			pinging:     isPinging,
+			pingTime:    pingTime,
+			via:         m.pingVia[key],
+			bastionDown: m.bastionDown[key],
+			history:     m.pingHistory[h.Alias],
+		})
 	}
 	m.list.SetItems(items)
+
+	if selectedAlias == "" {
+		return
+	}
+	for i, listItem := range m.list.VisibleItems() {
+		if listItem.(item).host.Alias == selectedAlias {
+			m.list.Select(i)
+			break
+		}
+	}
 }
 
 // GetSelectedHost returns the host selected for SSH connection
@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"context"
+	"os/user"
+	"sshbuddy/internal/config"
+	"sshbuddy/pkg/models"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sourceState tracks one enabled host source of the list
+type sourceState struct {
+	source  config.Source
+	loading bool                // A background load is in progress
+	loaded  bool                // result holds a result, possibly of an earlier load
+	result  config.SourceResult // Latest result
+	modTime time.Time           // Modification time of a watched source's files when its last load started
+}
+
+// sourceWatchInterval is how often the files of watched sources are checked
+// for changes
+const sourceWatchInterval = 2 * time.Second
+
+// newSourceSpinner creates the spinner shown next to sources still loading
+func newSourceSpinner() spinner.Model {
+	return spinner.New(
+		spinner.WithSpinner(spinner.MiniDot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(accentColor)),
+	)
+}
+
+// loadHosts makes cfg the current config and reloads its sources. Manual
+// hosts are loaded right away; every other source is loaded in the background
+// by the returned command and merged in when its SourceLoadedMsg arrives.
+// Until then, the hosts of the source's previous load stay in the list.
+func (m *Model) loadHosts(cfg *models.Config) tea.Cmd {
+	if cfg.Favorites == nil {
+		cfg.Favorites = make(map[string]bool)
+	}
+	m.sourceGeneration++
+
+	previous := make(map[string]*sourceState)
+	for _, state := range m.sources {
+		previous[state.source.Name()] = state
+	}
+
+	// Sources run in the background and may save the config they were created
+	// with (e.g. a refreshed Termix token), so they get a copy of their own
+	snapshot := *cfg
+	snapshot.Hosts = append([]models.Host(nil), cfg.Hosts...)
+	snapshot.Favorites = make(map[string]bool, len(cfg.Favorites))
+	for alias, favorite := range cfg.Favorites {
+		snapshot.Favorites[alias] = favorite
+	}
+
+	m.sources = nil
+	for _, source := range config.Sources(&snapshot) {
+		if !source.Enabled() {
+			continue
+		}
+		state := &sourceState{source: source}
+		if prev, ok := previous[source.Name()]; ok && prev.loaded {
+			state.result, state.loaded = prev.result, true
+		}
+		if source.Name() == "manual" {
+			state.result, state.loaded = config.LoadSource(context.Background(), source), true
+		} else {
+			state.loading = true
+			state.modTime = config.WatchedModTime(source)
+		}
+		m.sources = append(m.sources, state)
+	}
+
+	m.config = cfg
+	m.applySources()
+	return tea.Batch(m.pendingSourceLoads(), m.watchSources())
+}
+
+// reloadHosts re-reads the config file and reloads all sources. If the file
+// can't be read, the current hosts are kept.
+func (m *Model) reloadHosts() tea.Cmd {
+	cfg, err := config.LoadConfigRaw()
+	if err != nil {
+		return nil
+	}
+	return m.loadHosts(cfg)
+}
+
+// pendingSourceLoads returns the command loading every source marked as
+// loading, along with the spinner shown meanwhile
+func (m *Model) pendingSourceLoads() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, state := range m.sources {
+		if state.loading {
+			cmds = append(cmds, loadSource(m.sourceGeneration, state.source))
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(append(cmds, m.spinner.Tick)...)
+}
+
+// loadSource loads a source in the background
+func loadSource(generation int, source config.Source) tea.Cmd {
+	return func() tea.Msg {
+		return SourceLoadedMsg{
+			Generation: generation,
+			Result:     config.LoadSource(context.Background(), source),
+		}
+	}
+}
+
+// watchSources schedules the next check for changed files, if any source is
+// read from local files
+func (m *Model) watchSources() tea.Cmd {
+	for _, state := range m.sources {
+		if _, ok := state.source.(config.WatchedSource); ok {
+			generation := m.sourceGeneration
+			return tea.Tick(sourceWatchInterval, func(time.Time) tea.Msg {
+				return sourceWatchMsg{Generation: generation}
+			})
+		}
+	}
+	return nil
+}
+
+// reloadChangedSources reloads the watched sources whose files changed since
+// their last load started
+func (m *Model) reloadChangedSources() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, state := range m.sources {
+		if state.loading {
+			continue
+		}
+		if _, ok := state.source.(config.WatchedSource); !ok {
+			continue
+		}
+		if modTime := config.WatchedModTime(state.source); !modTime.Equal(state.modTime) {
+			state.modTime = modTime
+			state.loading = true
+			cmds = append(cmds, loadSource(m.sourceGeneration, state.source))
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(append(cmds, m.spinner.Tick)...)
+}
+
+// sourceLoading reports whether any source is still loading
+func (m *Model) sourceLoading() bool {
+	for _, state := range m.sources {
+		if state.loading {
+			return true
+		}
+	}
+	return false
+}
+
+// applySources merges the latest result of every source into the host list
+func (m *Model) applySources() {
+	var results []config.SourceResult
+	for _, state := range m.sources {
+		if state.loaded {
+			results = append(results, state.result)
+		}
+	}
+	config.ApplySourceResults(m.config, results)
+	m.refreshList()
+}
+
+// sourceBadges renders a spinner for every source still loading and an error
+// badge for every source that failed without cached hosts to fall back to
+func (m *Model) sourceBadges() string {
+	var badges []string
+	for _, state := range m.sources {
+		name := sourceShortName(state.source.Name())
+		switch {
+		case state.loading:
+			badges = append(badges, m.spinner.View()+lipgloss.NewStyle().Foreground(dimColor).Render(" "+name))
+		case state.result.Err != nil && state.result.StaleSince.IsZero():
+			badges = append(badges, lipgloss.NewStyle().Foreground(errorColor).Render("✗ "+name))
+		}
+	}
+	return strings.Join(badges, "  ")
+}
+
+// isDiscovered reports whether a host is only known from known_hosts
+func isDiscovered(host models.Host) bool {
+	return host.Source == models.KnownHostsSource && len(host.AvailableIn) <= 1
+}
+
+// promoteHost saves a discovered host as a manual host and reloads the list.
+// Without a user from the shell history, the current user is filled in, as
+// ssh would use it.
+func (m *Model) promoteHost(host models.Host) tea.Cmd {
+	rawConfig, err := config.LoadConfigRaw()
+	if err != nil {
+		return nil
+	}
+
+	promoted := models.Host{
+		Alias:    host.Alias,
+		Hostname: host.Hostname,
+		User:     host.User,
+		Port:     host.Port,
+		Source:   "manual",
+	}
+	if promoted.User == "" {
+		if current, err := user.Current(); err == nil {
+			promoted.User = current.Username
+		}
+	}
+	rawConfig.Hosts = append(rawConfig.Hosts, promoted)
+	if err := config.SaveConfig(rawConfig); err != nil {
+		return nil
+	}
+	return m.reloadHosts()
+}
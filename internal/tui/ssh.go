@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"sshbuddy/internal/ssh"
 	"sshbuddy/pkg/models"
 
@@ -8,24 +9,51 @@ import (
 )
 
 // PingHost wraps the SSH ping function as a Bubble Tea command
-func PingHost(host models.Host) tea.Cmd {
+func PingHost(host models.Host, opts ssh.PingOptions) tea.Cmd {
 	return func() tea.Msg {
-		result := ssh.Ping(host)
-		return PingResultMsg{
-			Host:     result.Host,
-			Status:   result.Status,
-			PingTime: result.PingTime,
+		return newPingResultMsg(ssh.Ping(host, opts))
+	}
+}
+
+// StartPingAll probes all hosts in the background with a bounded worker pool
+// until ctx is cancelled. The returned command delivers the first result;
+// each PingResultMsg of the round carries the command for the next one.
+func StartPingAll(ctx context.Context, generation int, hosts []models.Host, opts ssh.PingOptions) tea.Cmd {
+	results := make(chan ssh.PingResult)
+	go ssh.PingAll(ctx, hosts, opts, results)
+	return waitForPing(generation, results)
+}
+
+// waitForPing returns a command that delivers the next result of a ping
+// round, or PingRoundDoneMsg once the round is over
+func waitForPing(generation int, results <-chan ssh.PingResult) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-results
+		if !ok {
+			return PingRoundDoneMsg{Generation: generation}
 		}
+		msg := newPingResultMsg(result)
+		msg.Generation = generation
+		msg.next = waitForPing(generation, results)
+		return msg
 	}
 }
 
-// StartPingAll starts background ping for all hosts
-func StartPingAll(hosts []models.Host) tea.Cmd {
-	var cmds []tea.Cmd
-	for _, host := range hosts {
-		cmds = append(cmds, PingHost(host))
+// newPingResultMsg converts a probe result into a message
+func newPingResultMsg(result ssh.PingResult) PingResultMsg {
+	return PingResultMsg{
+		Host:        result.Host,
+		Status:      result.Status,
+		PingTime:    result.PingTime,
+		Latency:     result.Latency,
+		Via:         result.Via,
+		BastionDown: result.BastionDown(),
 	}
-	return tea.Batch(cmds...)
+}
+
+// pingOptions returns the probe options configured in cfg
+func pingOptions(cfg *models.Config) ssh.PingOptions {
+	return ssh.NewPingOptions(cfg)
 }
 
 // GetHostStatus returns a visual indicator for host status
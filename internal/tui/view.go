@@ -2,7 +2,10 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
+	"sshbuddy/pkg/models"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/lipgloss"
@@ -81,13 +84,29 @@ func (m Model) View() string {
 ╚═╗└─┐├─┤  ╠╩╗│ │ ││ ││└┬┘
 ╚═╝└─┘┴ ┴  ╚═╝└─┘─┴┘─┴┘ ┴`)
 
-	// Theme indicator
+	// Theme indicator, followed by the sources still loading or failed and a
+	// warning when hosts come from the offline cache
 	theme := GetCurrentTheme()
-	themeIndicator := lipgloss.NewStyle().
+	themeText := lipgloss.NewStyle().
 		Foreground(dimColor).
-		Width(boxWidth - 4).
-		Align(lipgloss.Center).
 		Render(fmt.Sprintf("Theme: %s", theme.Name))
+	var status []string
+	if badges := m.sourceBadges(); badges != "" {
+		status = append(status, badges)
+	}
+	if stale := m.staleIndicator(); stale != "" {
+		status = append(status, lipgloss.NewStyle().Foreground(errorColor).Render(stale))
+	}
+	if len(status) > 0 {
+		statusText := strings.Join(status, "  ")
+		if lipgloss.Width(themeText+"  "+statusText) <= boxWidth-4 {
+			themeText += "  " + statusText
+		} else {
+			// Drop the theme name rather than overflow the box
+			themeText = statusText
+		}
+	}
+	themeIndicator := lipgloss.PlaceHorizontal(boxWidth-4, lipgloss.Center, themeText)
 
 	separator := lipgloss.NewStyle().
 		Foreground(dimColor).
@@ -105,11 +124,16 @@ func (m Model) View() string {
 		keyStyle.Render("c") + descStyle.Render(":copy "),
 		keyStyle.Render("d") + descStyle.Render(":del "),
 		keyStyle.Render("f") + descStyle.Render(":fav "),
-		keyStyle.Render("p") + descStyle.Render(":ping "),
-		keyStyle.Render("s") + descStyle.Render(":settings "),
-		keyStyle.Render("/") + descStyle.Render(":search "),
-		keyStyle.Render("q") + descStyle.Render(":quit"),
 	}
+	if selected, ok := m.list.SelectedItem().(item); ok && isDiscovered(selected.host) {
+		keyBindings = append(keyBindings, keyStyle.Render("a")+descStyle.Render(":add "))
+	}
+	keyBindings = append(keyBindings,
+		keyStyle.Render("p")+descStyle.Render(":ping "),
+		keyStyle.Render("s")+descStyle.Render(":settings "),
+		keyStyle.Render("/")+descStyle.Render(":search "),
+		keyStyle.Render("q")+descStyle.Render(":quit"),
+	)
 	footer := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), true, false, false, false).
 		BorderForeground(borderColor).
@@ -173,9 +197,36 @@ func (m Model) View() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, mainBox)
 }
 
+// staleIndicator describes the sources served from the offline cache, e.g.
+// "⚠ termix stale since 14:02", or returns "" if all sources are current
+func (m *Model) staleIndicator() string {
+	if m.config == nil || len(m.config.StaleSources) == 0 {
+		return ""
+	}
+
+	var parts []string
+	now := time.Now()
+	for _, name := range m.config.StaleSourceNames() {
+		since := m.config.StaleSources[name]
+		layout := "15:04"
+		if since.Year() != now.Year() || since.YearDay() != now.YearDay() {
+			layout = "Jan 2 15:04"
+		}
+		parts = append(parts, fmt.Sprintf("%s stale since %s", sourceShortName(name), since.Format(layout)))
+	}
+	return "⚠ " + strings.Join(parts, ", ")
+}
+
 // renderTwoColumnList renders the host list in a two-column layout
 func (m *Model) renderTwoColumnList() string {
 	items := m.list.VisibleItems()
+	if len(items) == 0 && m.sourceLoading() {
+		return lipgloss.NewStyle().
+			Foreground(dimColor).
+			Italic(true).
+			Padding(2, 0).
+			Render("Loading hosts...")
+	}
 	if len(items) == 0 {
 		emptyMsg := lipgloss.NewStyle().
 			Foreground(dimColor).
@@ -220,19 +271,7 @@ func (m *Model) renderTwoColumnList() string {
 			isSelected := i == cursor
 
 			// Format the item with status
-			var statusText string
-			if itm.pinging {
-				statusText = statusPingingStyle.Render("●")
-			} else {
-				switch itm.status {
-				case "🟢":
-					statusText = statusOnlineStyle.Render("●")
-				case "🔴":
-					statusText = statusOfflineStyle.Render("●")
-				default:
-					statusText = statusUnknownStyle.Render("○")
-				}
-			}
+			statusText := itm.statusIndicator()
 
 			// Title line - build with alias and ping time
 			alias := itm.host.Alias
@@ -247,8 +286,8 @@ func (m *Model) renderTwoColumnList() string {
 			styledAlias := lipgloss.NewStyle().Foreground(primaryColor).Render(alias)
 
 			pingTimeStr := ""
-			if itm.pingTime != "" {
-				pingTimeStr = lipgloss.NewStyle().Foreground(dimColor).Render(fmt.Sprintf(" (%s)", itm.pingTime))
+			if note := itm.statusNote(); note != "" {
+				pingTimeStr = lipgloss.NewStyle().Foreground(dimColor).Render(fmt.Sprintf(" (%s)", note))
 			}
 
 			port := itm.host.Port
@@ -256,10 +295,22 @@ func (m *Model) renderTwoColumnList() string {
 				port = "22"
 			}
 
-			// Description line - truncate to fit
-			hostInfo := fmt.Sprintf("%s@%s:%s", itm.host.User, itm.host.Hostname, port)
-			if len(hostInfo) > 28 {
-				hostInfo = hostInfo[:25] + "..."
+			// Description line - truncate to fit, leaving room for the
+			// latency sparkline and packet loss once the host has been probed
+			hostInfo := fmt.Sprintf("%s:%s", itm.host.Destination(), port)
+			if itm.host.IsDocker() {
+				hostInfo = "docker:" + itm.host.Hostname
+			}
+			summary := itm.historySummary(8)
+			maxInfoLen := 28
+			if summary != "" {
+				maxInfoLen = columnWidth - 5 - lipgloss.Width(summary)
+			}
+			if len(hostInfo) > maxInfoLen {
+				hostInfo = hostInfo[:maxInfoLen-3] + "..."
+			}
+			if summary != "" {
+				hostInfo += strings.Repeat(" ", columnWidth-4-len(hostInfo)-lipgloss.Width(summary)) + summary
 			}
 
 			// Source line - render with colors and favorite indicator
@@ -377,34 +428,6 @@ func renderSource(primarySource string, availableIn []string, isFavorite bool, m
 		primarySource = "manual"
 	}
 
-	// Helper to get icon for a source
-	getIcon := func(source string) string {
-		switch source {
-		case "manual", "sshbuddy":
-			return "◆" // Diamond for manual/sshbuddy
-		case "ssh-config":
-			return "■" // Square for config file
-		case "termix":
-			return "▲" // Triangle for API/cloud
-		default:
-			return "○"
-		}
-	}
-
-	// Helper to get display name
-	getDisplayName := func(source string) string {
-		switch source {
-		case "manual", "sshbuddy":
-			return "sshbuddy"
-		case "ssh-config":
-			return "config"
-		case "termix":
-			return "termix"
-		default:
-			return source
-		}
-	}
-
 	// Build the source display showing all sources
 	var sourceParts []string
 
@@ -416,7 +439,7 @@ func renderSource(primarySource string, availableIn []string, isFavorite bool, m
 		if src == "termix" {
 			hasTermix = true
 		}
-		if src == "ssh-config" {
+		if models.IsSSHConfigSource(src) {
 			hasSSH = true
 		}
 		if src == "manual" || src == "sshbuddy" {
@@ -426,20 +449,20 @@ func renderSource(primarySource string, availableIn []string, isFavorite bool, m
 
 	if hasTermix && hasSSH && hasManual {
 		// Show all icons + "All"
-		sourceParts = append(sourceParts, getIcon("termix"))
-		sourceParts = append(sourceParts, getIcon("ssh-config"))
-		sourceParts = append(sourceParts, getIcon("manual"))
+		sourceParts = append(sourceParts, sourceIcon("termix"))
+		sourceParts = append(sourceParts, sourceIcon("ssh-config"))
+		sourceParts = append(sourceParts, sourceIcon("manual"))
 		sourceParts = append(sourceParts, "All")
 	} else if len(availableIn) == 0 {
 		// Fallback to primary source if AvailableIn is empty
-		icon := getIcon(primarySource)
-		displayName := getDisplayName(primarySource)
+		icon := sourceIcon(primarySource)
+		displayName := sourceShortName(primarySource)
 		sourceParts = append(sourceParts, icon+" "+displayName)
 	} else {
 		// Show all sources with icon + name
 		for _, src := range availableIn {
-			icon := getIcon(src)
-			displayName := getDisplayName(src)
+			icon := sourceIcon(src)
+			displayName := sourceShortName(src)
 			sourceParts = append(sourceParts, icon+" "+displayName)
 		}
 	}
@@ -458,6 +481,110 @@ func renderSource(primarySource string, availableIn []string, isFavorite bool, m
 	return sourceText
 }
 
+// selectableSources returns the sources offered when connecting to host:
+// the merged variant first, if the host was merged, then every source
+func selectableSources(host models.Host) []string {
+	if host.Variants[models.MergedSource] != nil {
+		return append([]string{models.MergedSource}, host.AvailableIn...)
+	}
+	return host.AvailableIn
+}
+
+// sourceIcon returns the icon used for a host source
+func sourceIcon(source string) string {
+	switch {
+	case source == models.MergedSource:
+		return "✦" // Combined from several sources
+	case source == "manual" || source == "sshbuddy":
+		return "◆" // Diamond for manual/sshbuddy
+	case models.IsSSHConfigSource(source):
+		return "■" // Square for config file
+	case source == "termix":
+		return "▲" // Triangle for API/cloud
+	case models.IsHTTPSource(source):
+		return "◈" // Framed diamond for HTTP host lists
+	case models.IsExecSource(source):
+		return "▸" // Pointer for inventory commands
+	case models.IsAnsibleSource(source):
+		return "◇" // Hollow diamond for Ansible inventories
+	case models.IsTerraformSource(source):
+		return "▣" // Filled square for Terraform states
+	case source == models.VagrantSource:
+		return "▢" // Box for Vagrant machines
+	case source == models.DockerSource:
+		return "◫" // Split square for Docker containers
+	case source == models.KnownHostsSource:
+		return "◌" // Dotted circle for hosts discovered in known_hosts
+	default:
+		return "○"
+	}
+}
+
+// sourceShortName returns the compact source label shown in the host list
+func sourceShortName(source string) string {
+	switch {
+	case source == "manual" || source == "sshbuddy":
+		return "sshbuddy"
+	case source == "ssh-config":
+		return "config"
+	case models.IsSSHConfigSource(source):
+		// Additional config files are labelled by file name
+		return "config:" + filepath.Base(strings.TrimPrefix(source, "ssh-config:"))
+	case source == "termix":
+		return "termix"
+	case models.IsHTTPSource(source):
+		return models.HTTPSourceLabel(source)
+	case models.IsExecSource(source):
+		return models.ExecSourceLabel(source)
+	case models.IsAnsibleSource(source):
+		return models.AnsibleSourceLabel(source)
+	case models.IsTerraformSource(source):
+		return models.TerraformSourceLabel(source)
+	case source == models.VagrantSource:
+		return "vagrant"
+	case source == models.DockerSource:
+		return "docker"
+	case source == models.KnownHostsSource:
+		return "discovered"
+	case source == models.MergedSource:
+		return "merged"
+	default:
+		return source
+	}
+}
+
+// sourceLongName returns the source label shown in dialogs
+func sourceLongName(source string) string {
+	switch {
+	case source == "manual" || source == "sshbuddy":
+		return "SSHBuddy"
+	case source == "ssh-config":
+		return "SSH Config"
+	case models.IsSSHConfigSource(source):
+		return "SSH Config (" + strings.TrimPrefix(source, "ssh-config:") + ")"
+	case source == "termix":
+		return "Termix"
+	case models.IsHTTPSource(source):
+		return "HTTP (" + models.HTTPSourceLabel(source) + ")"
+	case models.IsExecSource(source):
+		return "Command (" + models.ExecSourceLabel(source) + ")"
+	case models.IsAnsibleSource(source):
+		return "Ansible (" + models.AnsibleSourceLabel(source) + ")"
+	case models.IsTerraformSource(source):
+		return "Terraform (" + models.TerraformSourceLabel(source) + ")"
+	case source == models.VagrantSource:
+		return "Vagrant"
+	case source == models.DockerSource:
+		return "Docker"
+	case source == models.KnownHostsSource:
+		return "Discovered (known_hosts)"
+	case source == models.MergedSource:
+		return "Merged (fields combined from all sources)"
+	default:
+		return source
+	}
+}
+
 // renderDeleteConfirmation renders the delete confirmation dialog
 func (m Model) renderDeleteConfirmation() string {
 	if m.deleteConfirmHost == nil {
@@ -477,7 +604,7 @@ func (m Model) renderDeleteConfirmation() string {
 		Foreground(textColor).
 		MarginTop(1).
 		MarginBottom(1).
-		Render(fmt.Sprintf("Alias: %s\nHost: %s@%s", host.Alias, host.User, host.Hostname))
+		Render(fmt.Sprintf("Alias: %s\nHost: %s", host.Alias, host.Destination()))
 
 	// Confirmation message
 	confirmMsg := lipgloss.NewStyle().
@@ -633,7 +760,7 @@ func (m Model) renderSourceSelect() string {
 		Foreground(textColor).
 		MarginTop(1).
 		MarginBottom(1).
-		Render(fmt.Sprintf("Host: %s (%s@%s)", host.Alias, host.User, host.Hostname))
+		Render(fmt.Sprintf("Host: %s (%s)", host.Alias, host.Destination()))
 
 	// Instructions
 	instructions := lipgloss.NewStyle().
@@ -643,23 +770,10 @@ func (m Model) renderSourceSelect() string {
 
 	// Source list
 	var sourceItems []string
-	for i, source := range host.AvailableIn {
+	for i, source := range selectableSources(*host) {
 		// Get icon and name
-		var icon, name string
-		switch source {
-		case "termix":
-			icon = "▲"
-			name = "Termix"
-		case "ssh-config":
-			icon = "■"
-			name = "SSH Config"
-		case "manual", "sshbuddy":
-			icon = "◆"
-			name = "SSHBuddy"
-		default:
-			icon = "○"
-			name = source
-		}
+		icon := sourceIcon(source)
+		name := sourceLongName(source)
 
 		// Format item
 		var item string
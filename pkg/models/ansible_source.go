@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnsibleSourceConfig configures a source that reads the hosts of an Ansible
+// inventory file, in INI, YAML or JSON format
+type AnsibleSourceConfig struct {
+	Name    string `json:"name"` // Identifies the source as "ansible:<name>"
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"` // Inventory file; .yml/.yaml and .json are read as YAML and JSON, anything else as INI
+}
+
+// ansibleSourcePrefix starts the names of Ansible sources
+const ansibleSourcePrefix = "ansible:"
+
+// SourceName returns the source name of the Ansible source
+func (c AnsibleSourceConfig) SourceName() string {
+	return ansibleSourcePrefix + c.Name
+}
+
+// IsAnsibleSource reports whether a source name refers to an Ansible source
+func IsAnsibleSource(source string) bool {
+	return strings.HasPrefix(source, ansibleSourcePrefix)
+}
+
+// AnsibleSourceLabel returns the configured name of an Ansible source
+func AnsibleSourceLabel(source string) string {
+	return strings.TrimPrefix(source, ansibleSourcePrefix)
+}
+
+// validateAnsibleSources checks the Ansible sources of a config
+func validateAnsibleSources(sources []AnsibleSourceConfig) []ValidationError {
+	var errors []ValidationError
+	seen := make(map[string]bool)
+	for i, source := range sources {
+		name := strings.TrimSpace(source.Name)
+		switch {
+		case name == "":
+			errors = append(errors, ValidationError{
+				Field:   "Ansible",
+				Message: fmt.Sprintf("ansible source #%d needs a name", i+1),
+				Index:   -1,
+			})
+		case seen[name]:
+			errors = append(errors, ValidationError{
+				Field:   "Ansible",
+				Message: fmt.Sprintf("duplicate ansible source name '%s'", name),
+				Index:   -1,
+			})
+		}
+		seen[name] = true
+
+		if strings.TrimSpace(source.Path) == "" {
+			errors = append(errors, ValidationError{
+				Field:   "Ansible",
+				Message: fmt.Sprintf("ansible source '%s' needs an inventory path", name),
+				Index:   -1,
+			})
+		}
+	}
+	return errors
+}
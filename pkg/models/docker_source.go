@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DockerSource is the source name of hosts for running Docker containers
+const DockerSource = "docker"
+
+// DockerSourceConfig configures the source that lists the running Docker
+// containers with the docker CLI. Its hosts connect with docker exec.
+type DockerSourceConfig struct {
+	Enabled        bool   `json:"enabled"`
+	User           string `json:"user,omitempty"`           // User to exec as, defaults to the container's user
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"` // Defaults to DefaultDockerTimeoutSeconds
+}
+
+// DefaultDockerTimeoutSeconds limits how long `docker ps` may run
+const DefaultDockerTimeoutSeconds = 10
+
+// Timeout returns how long `docker ps` may run
+func (c DockerSourceConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultDockerTimeoutSeconds * time.Second
+}
+
+// validate checks the Docker source settings
+func (c DockerSourceConfig) validate() []ValidationError {
+	if c.TimeoutSeconds < 0 {
+		return []ValidationError{{
+			Field:   "Docker",
+			Message: fmt.Sprintf("Docker timeout must not be negative, got %d", c.TimeoutSeconds),
+			Index:   -1,
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExecSourceConfig configures a source that runs a command whose standard
+// output is a JSON array of hosts, in the same format as Config.Hosts
+type ExecSourceConfig struct {
+	Name           string `json:"name"` // Identifies the source as "exec:<name>"
+	Enabled        bool   `json:"enabled"`
+	Command        string `json:"command"`                  // Command line, run with sh -c
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"` // Defaults to DefaultExecTimeoutSeconds
+}
+
+// DefaultExecTimeoutSeconds limits how long an exec source's command may run
+const DefaultExecTimeoutSeconds = 10
+
+// execSourcePrefix starts the names of exec sources
+const execSourcePrefix = "exec:"
+
+// SourceName returns the source name of the exec source
+func (c ExecSourceConfig) SourceName() string {
+	return execSourcePrefix + c.Name
+}
+
+// Timeout returns how long the command may run
+func (c ExecSourceConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultExecTimeoutSeconds * time.Second
+}
+
+// IsExecSource reports whether a source name refers to an exec source
+func IsExecSource(source string) bool {
+	return strings.HasPrefix(source, execSourcePrefix)
+}
+
+// ExecSourceLabel returns the configured name of an exec source
+func ExecSourceLabel(source string) string {
+	return strings.TrimPrefix(source, execSourcePrefix)
+}
+
+// validateExecSources checks the exec sources of a config
+func validateExecSources(sources []ExecSourceConfig) []ValidationError {
+	var errors []ValidationError
+	seen := make(map[string]bool)
+	for i, source := range sources {
+		name := strings.TrimSpace(source.Name)
+		switch {
+		case name == "":
+			errors = append(errors, ValidationError{
+				Field:   "Exec",
+				Message: fmt.Sprintf("exec source #%d needs a name", i+1),
+				Index:   -1,
+			})
+		case seen[name]:
+			errors = append(errors, ValidationError{
+				Field:   "Exec",
+				Message: fmt.Sprintf("duplicate exec source name '%s'", name),
+				Index:   -1,
+			})
+		}
+		seen[name] = true
+
+		if strings.TrimSpace(source.Command) == "" {
+			errors = append(errors, ValidationError{
+				Field:   "Exec",
+				Message: fmt.Sprintf("exec source '%s' needs a command", name),
+				Index:   -1,
+			})
+		}
+		if source.TimeoutSeconds < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "Exec",
+				Message: fmt.Sprintf("exec source '%s' timeout must not be negative", name),
+				Index:   -1,
+			})
+		}
+	}
+	return errors
+}
@@ -2,8 +2,10 @@ package models
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Host struct {
@@ -19,15 +21,72 @@ type Host struct {
 	Favorite     bool             `json:"favorite,omitempty"`      // Mark as favorite
 	DefaultPath  string           `json:"default_path,omitempty"`  // Default directory to cd into
 	Variants     map[string]*Host `json:"-"`                       // Configuration variants by source (not saved to JSON)
+
+	// Forwarding and keepalive settings, in ssh_config syntax
+	LocalForwards       []string `json:"local_forwards,omitempty"`        // e.g. "8080 localhost:80"
+	RemoteForwards      []string `json:"remote_forwards,omitempty"`       // e.g. "9090 localhost:3000"
+	DynamicForwards     []string `json:"dynamic_forwards,omitempty"`      // e.g. "1080"
+	ForwardAgent        bool     `json:"forward_agent,omitempty"`         // Forward the SSH agent (-A)
+	ServerAliveInterval string   `json:"server_alive_interval,omitempty"` // Keepalive interval in seconds
+
+	// Any other ssh_config options, passed to ssh as -o Key=Value
+	Options map[string]string `json:"options,omitempty"`
+
+	PingMethod string `json:"ping_method,omitempty"` // Overrides the global ping method for this host
+
+	ConnectMode string `json:"connect_mode,omitempty"` // How to connect: "ssh" (default) or "docker"
+}
+
+// Connection modes
+const (
+	ConnectModeSSH    = "ssh"    // Connect with ssh
+	ConnectModeDocker = "docker" // Open a shell with docker exec; Hostname is the container
+)
+
+// IsDocker reports whether the host is a container connected to with docker exec
+func (h *Host) IsDocker() bool {
+	return h.ConnectMode == ConnectModeDocker
+}
+
+// Destination returns "user@hostname", or just the hostname when no user is
+// set and ssh picks the user itself
+func (h *Host) Destination() string {
+	if h.User == "" {
+		return h.Hostname
+	}
+	return h.User + "@" + h.Hostname
 }
 
 type Config struct {
-	Hosts     []Host          `json:"hosts"`
-	Theme     string          `json:"theme,omitempty"`
-	Sources   SourcesConfig   `json:"sources"`
-	Termix    TermixConfig    `json:"termix"`
-	SSH       SSHConfig       `json:"ssh"`
-	Favorites map[string]bool `json:"favorites,omitempty"` // Map of alias -> favorite status
+	Hosts      []Host                  `json:"hosts"`
+	Theme      string                  `json:"theme,omitempty"`
+	Sources    SourcesConfig           `json:"sources"`
+	Termix     TermixConfig            `json:"termix"`
+	SSH        SSHConfig               `json:"ssh"`
+	Ping       PingConfig              `json:"ping"`
+	Merge      MergeConfig             `json:"merge"`
+	KnownHosts KnownHostsConfig        `json:"knownHosts"`
+	Exec       []ExecSourceConfig      `json:"exec,omitempty"`      // Sources running inventory commands
+	HTTP       []HTTPSourceConfig      `json:"http,omitempty"`      // Sources fetching JSON host lists
+	Ansible    []AnsibleSourceConfig   `json:"ansible,omitempty"`   // Sources reading Ansible inventories
+	Terraform  []TerraformSourceConfig `json:"terraform,omitempty"` // Sources reading Terraform states
+	Vagrant    VagrantSourceConfig     `json:"vagrant"`
+	Docker     DockerSourceConfig      `json:"docker"`
+	Favorites  map[string]bool         `json:"favorites,omitempty"` // Map of alias -> favorite status
+
+	// StaleSources lists the sources that failed to load and were served from
+	// the offline cache, with the time of the cached result (not saved to JSON)
+	StaleSources map[string]time.Time `json:"-"`
+}
+
+// StaleSourceNames returns the names of the stale sources in sorted order
+func (c *Config) StaleSourceNames() []string {
+	names := make([]string, 0, len(c.StaleSources))
+	for name := range c.StaleSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 type SourcesConfig struct {
@@ -44,8 +103,115 @@ type TermixConfig struct {
 }
 
 type SSHConfig struct {
-	Enabled    bool   `json:"enabled"`
-	ConfigPath string `json:"configPath,omitempty"`
+	Enabled        bool     `json:"enabled"`
+	ConfigPath     string   `json:"configPath,omitempty"`
+	ConfigPaths    []string `json:"configPaths,omitempty"`    // Additional config files, each loaded as its own source
+	ResolveWithSSH bool     `json:"resolveWithSsh,omitempty"` // Fill host settings from `ssh -G` output
+}
+
+// PingConfig controls how host reachability is checked
+type PingConfig struct {
+	Method         string `json:"method,omitempty"`         // "tcp" (default), "ssh" or "icmp"
+	TimeoutMs      int    `json:"timeoutMs,omitempty"`      // Probe timeout, defaults to DefaultPingTimeoutMs
+	Concurrency    int    `json:"concurrency,omitempty"`    // Probes run at once, defaults to DefaultPingConcurrency
+	RefreshSeconds int    `json:"refreshSeconds,omitempty"` // Re-check all hosts this often (0 = only on demand)
+}
+
+// Ping methods
+const (
+	PingMethodTCP  = "tcp"  // Connect to the SSH port
+	PingMethodSSH  = "ssh"  // Connect to the SSH port and read the SSH banner
+	PingMethodICMP = "icmp" // Run the system ping command
+)
+
+// PingMethods lists the valid ping methods, default first
+var PingMethods = []string{PingMethodTCP, PingMethodSSH, PingMethodICMP}
+
+// Ping defaults used when the config leaves a setting unset
+const (
+	DefaultPingTimeoutMs   = 2000
+	DefaultPingConcurrency = 16
+)
+
+// IsValidPingMethod reports whether method is a known ping method
+func IsValidPingMethod(method string) bool {
+	for _, valid := range PingMethods {
+		if method == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodFor returns the ping method to use for host, honouring its override
+func (c PingConfig) MethodFor(host Host) string {
+	if host.PingMethod != "" {
+		return host.PingMethod
+	}
+	if c.Method != "" {
+		return c.Method
+	}
+	return PingMethodTCP
+}
+
+// Timeout returns the probe timeout in milliseconds
+func (c PingConfig) Timeout() int {
+	if c.TimeoutMs > 0 {
+		return c.TimeoutMs
+	}
+	return DefaultPingTimeoutMs
+}
+
+// Workers returns how many probes may run at once
+func (c PingConfig) Workers() int {
+	if c.Concurrency > 0 {
+		return c.Concurrency
+	}
+	return DefaultPingConcurrency
+}
+
+// DefaultSSHConfigPath is used when no config path is configured
+const DefaultSSHConfigPath = "~/.ssh/config"
+
+// Paths returns all SSH config files to read, the primary config first
+func (c SSHConfig) Paths() []string {
+	primary := strings.TrimSpace(c.ConfigPath)
+	if primary == "" {
+		primary = DefaultSSHConfigPath
+	}
+
+	paths := []string{primary}
+	for _, path := range c.ConfigPaths {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// SourceName returns the source name used for hosts from the config file at
+// index i of Paths(). The primary config is "ssh-config", additional files are
+// "ssh-config:<path>" so each one is tracked separately in Host.AvailableIn.
+func (c SSHConfig) SourceName(i int) string {
+	if i == 0 {
+		return "ssh-config"
+	}
+	return "ssh-config:" + c.Paths()[i]
+}
+
+// PathForSource returns the config file path for an SSH config source name
+func (c SSHConfig) PathForSource(source string) (string, bool) {
+	for i, path := range c.Paths() {
+		if c.SourceName(i) == source {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// IsSSHConfigSource reports whether a source name refers to an SSH config file
+func IsSSHConfigSource(source string) bool {
+	return source == "ssh-config" || strings.HasPrefix(source, "ssh-config:")
 }
 
 // ValidationError represents a config validation error
@@ -84,8 +250,8 @@ func (h *Host) Validate() []ValidationError {
 		})
 	}
 
-	// User is required
-	if strings.TrimSpace(h.User) == "" {
+	// User is required, except for containers, which default to their own user
+	if strings.TrimSpace(h.User) == "" && !h.IsDocker() {
 		errors = append(errors, ValidationError{
 			Field:   "User",
 			Message: "user is required",
@@ -111,9 +277,103 @@ func (h *Host) Validate() []ValidationError {
 		}
 	}
 
+	// Forward specs use ssh_config syntax: "[bind_address:]port host:hostport"
+	for _, spec := range h.LocalForwards {
+		if len(strings.Fields(spec)) != 2 {
+			errors = append(errors, ValidationError{
+				Field:   "LocalForward",
+				Message: fmt.Sprintf("'%s' must be '[bind:]port host:hostport'", spec),
+				Index:   -1,
+			})
+		}
+	}
+	for _, spec := range h.RemoteForwards {
+		// A single argument is a dynamic remote forward (SOCKS on the server side)
+		if n := len(strings.Fields(spec)); n != 1 && n != 2 {
+			errors = append(errors, ValidationError{
+				Field:   "RemoteForward",
+				Message: fmt.Sprintf("'%s' must be '[bind:]port [host:hostport]'", spec),
+				Index:   -1,
+			})
+		}
+	}
+	for _, spec := range h.DynamicForwards {
+		if len(strings.Fields(spec)) != 1 {
+			errors = append(errors, ValidationError{
+				Field:   "DynamicForward",
+				Message: fmt.Sprintf("'%s' must be '[bind:]port'", spec),
+				Index:   -1,
+			})
+		}
+	}
+
+	// ServerAliveInterval validation (if provided)
+	if h.ServerAliveInterval != "" {
+		interval, err := strconv.Atoi(h.ServerAliveInterval)
+		if err != nil || interval < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "ServerAliveInterval",
+				Message: "keepalive interval must be a non-negative number of seconds",
+				Index:   -1,
+			})
+		}
+	}
+
+	// Ping method override (if provided)
+	if h.PingMethod != "" && !IsValidPingMethod(h.PingMethod) {
+		errors = append(errors, ValidationError{
+			Field:   "PingMethod",
+			Message: fmt.Sprintf("ping method must be one of: %s", strings.Join(PingMethods, ", ")),
+			Index:   -1,
+		})
+	}
+
+	// Connection mode (if provided)
+	if h.ConnectMode != "" && h.ConnectMode != ConnectModeSSH && h.ConnectMode != ConnectModeDocker {
+		errors = append(errors, ValidationError{
+			Field:   "ConnectMode",
+			Message: fmt.Sprintf("connect mode must be one of: %s, %s", ConnectModeSSH, ConnectModeDocker),
+			Index:   -1,
+		})
+	}
+
+	// Extra options must be known ssh_config keywords without a field of their own
+	for _, key := range h.SortedOptionKeys() {
+		canonical, ok := CanonicalSSHOption(key)
+		if !ok {
+			errors = append(errors, ValidationError{
+				Field:   "Options",
+				Message: fmt.Sprintf("unknown SSH option '%s'", key),
+				Index:   -1,
+			})
+		} else if field, isField := optionFields[canonical]; isField {
+			errors = append(errors, ValidationError{
+				Field:   "Options",
+				Message: fmt.Sprintf("set '%s' with the %s field (%s in config.json), not as an SSH option", key, field.label, field.json),
+				Index:   -1,
+			})
+		} else if strings.TrimSpace(h.Options[key]) == "" {
+			errors = append(errors, ValidationError{
+				Field:   "Options",
+				Message: fmt.Sprintf("SSH option '%s' needs a value", key),
+				Index:   -1,
+			})
+		}
+	}
+
 	return errors
 }
 
+// SortedOptionKeys returns the keys of h.Options in a stable order
+func (h *Host) SortedOptionKeys() []string {
+	keys := make([]string, 0, len(h.Options))
+	for key := range h.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Validate checks if the entire config is valid
 func (c *Config) Validate() []ValidationError {
 	var errors []ValidationError
@@ -161,5 +421,79 @@ func (c *Config) Validate() []ValidationError {
 		}
 	}
 
+	// Validate ping settings if provided
+	if c.Ping.Method != "" && !IsValidPingMethod(c.Ping.Method) {
+		errors = append(errors, ValidationError{
+			Field:   "Ping",
+			Message: fmt.Sprintf("invalid ping method '%s' (valid: %s)", c.Ping.Method, strings.Join(PingMethods, ", ")),
+			Index:   -1,
+		})
+	}
+	if c.Ping.TimeoutMs < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "Ping",
+			Message: "ping timeout must not be negative",
+			Index:   -1,
+		})
+	}
+	if c.Ping.Concurrency < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "Ping",
+			Message: "ping concurrency must not be negative",
+			Index:   -1,
+		})
+	}
+	if c.Ping.RefreshSeconds < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "Ping",
+			Message: "ping refresh interval must not be negative",
+			Index:   -1,
+		})
+	}
+
+	// Validate merge settings if provided
+	if c.Merge.Strategy != "" && c.Merge.Strategy != MergeWinner && c.Merge.Strategy != MergeFieldMerge {
+		errors = append(errors, ValidationError{
+			Field:   "Merge",
+			Message: fmt.Sprintf("invalid merge strategy '%s' (valid: %s, %s)", c.Merge.Strategy, MergeWinner, MergeFieldMerge),
+			Index:   -1,
+		})
+	}
+	for field, strategy := range c.Merge.Fields {
+		if _, ok := mergeableFields[field]; !ok {
+			var valid []string
+			for _, name := range MergeableFields() {
+				if !FollowsHostname(name) {
+					valid = append(valid, name)
+				}
+			}
+			errors = append(errors, ValidationError{
+				Field:   "Merge",
+				Message: fmt.Sprintf("unknown merge field '%s' (valid: %s)", field, strings.Join(valid, ", ")),
+				Index:   -1,
+			})
+		} else if FollowsHostname(field) {
+			errors = append(errors, ValidationError{
+				Field:   "Merge",
+				Message: fmt.Sprintf("merge field '%s' always comes from the source of the hostname; set a strategy for 'hostname' instead", field),
+				Index:   -1,
+			})
+		} else if strings.TrimSpace(strategy) == "" {
+			errors = append(errors, ValidationError{
+				Field:   "Merge",
+				Message: fmt.Sprintf("merge field '%s' needs a strategy or source", field),
+				Index:   -1,
+			})
+		}
+	}
+
+	// Validate exec, HTTP, Ansible, Terraform, Vagrant and Docker sources
+	errors = append(errors, validateExecSources(c.Exec)...)
+	errors = append(errors, validateHTTPSources(c.HTTP)...)
+	errors = append(errors, validateAnsibleSources(c.Ansible)...)
+	errors = append(errors, validateTerraformSources(c.Terraform)...)
+	errors = append(errors, c.Vagrant.validate()...)
+	errors = append(errors, c.Docker.validate()...)
+
 	return errors
 }
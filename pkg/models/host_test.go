@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		wantErr string // Substring of the error, "" for none
+	}{
+		{"known option", map[string]string{"StrictHostKeyChecking": "accept-new"}, ""},
+		{"several values in one", map[string]string{"SendEnv": "LANG LC_*"}, ""},
+		{"unknown option", map[string]string{"NoSuchOption": "yes"}, "unknown SSH option"},
+		{"missing value", map[string]string{"Compression": " "}, "needs a value"},
+		{"port has a field", map[string]string{"Port": "2222"}, "Port field (port in config.json)"},
+		{"any spelling", map[string]string{"hostname": "10.0.0.2"}, "Hostname field"},
+		{"forwards have a field", map[string]string{"LocalForward": "8080 localhost:80"}, "Local Forwards field"},
+		{"keepalive has a field", map[string]string{"ServerAliveInterval": "30"}, "server_alive_interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := Host{Alias: "web", Hostname: "10.0.0.1", User: "admin", Options: tt.options}
+			errs := host.Validate()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Message, tt.wantErr) {
+				t.Fatalf("got %v, want one error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMergeFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		wantErr string // Substring of the error, "" for none
+	}{
+		{"source per field", map[string]string{"hostname": "termix", "user": "field-merge"}, ""},
+		{"unknown field", map[string]string{"hostnme": "termix"}, "unknown merge field 'hostnme'"},
+		{"connect mode follows hostname", map[string]string{"connect_mode": "docker"}, "source of the hostname"},
+		{"ping method follows hostname", map[string]string{"ping_method": "field-merge"}, "source of the hostname"},
+		{"missing strategy", map[string]string{"port": " "}, "needs a strategy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Merge: MergeConfig{Fields: tt.fields}}
+			errs := cfg.Validate()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Message, tt.wantErr) {
+				t.Fatalf("got %v, want one error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
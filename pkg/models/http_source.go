@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTTPSourceConfig configures a source that fetches a JSON host list over HTTP
+type HTTPSourceConfig struct {
+	Name           string            `json:"name"` // Identifies the source as "http:<name>"
+	Enabled        bool              `json:"enabled"`
+	URL            string            `json:"url"`
+	Token          string            `json:"token,omitempty"`          // Sent as a bearer token
+	Username       string            `json:"username,omitempty"`       // Basic auth, if no token is set
+	Password       string            `json:"password,omitempty"`       // Basic auth password
+	HostsPath      string            `json:"hostsPath,omitempty"`      // JSONPath of the host array, default "$"
+	Fields         map[string]string `json:"fields,omitempty"`         // JSONPath of each host field, relative to a host
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty"` // Defaults to DefaultHTTPTimeoutSeconds
+}
+
+// DefaultHTTPTimeoutSeconds limits how long fetching an HTTP source may take
+const DefaultHTTPTimeoutSeconds = 10
+
+// httpSourcePrefix starts the names of HTTP sources
+const httpSourcePrefix = "http:"
+
+// HTTPMappableFields lists the host fields an HTTP source can map, by their
+// JSON name. By default each is read from the host's property of that name.
+var HTTPMappableFields = []string{"alias", "hostname", "user", "port", "tags", "identity_file", "proxy_jump", "default_path"}
+
+// SourceName returns the source name of the HTTP source
+func (c HTTPSourceConfig) SourceName() string {
+	return httpSourcePrefix + c.Name
+}
+
+// Timeout returns how long a fetch may take
+func (c HTTPSourceConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultHTTPTimeoutSeconds * time.Second
+}
+
+// FieldPath returns the JSONPath a host field is read from
+func (c HTTPSourceConfig) FieldPath(field string) string {
+	if path := strings.TrimSpace(c.Fields[field]); path != "" {
+		return path
+	}
+	return "$." + field
+}
+
+// CacheKey identifies what the source fetches: a cached result is only
+// valid for the same URL, host path and field mapping
+func (c HTTPSourceConfig) CacheKey() string {
+	fields := make([]string, 0, len(c.Fields))
+	for field, path := range c.Fields {
+		fields = append(fields, field+"="+path)
+	}
+	sort.Strings(fields)
+	return c.URL + " " + c.HostsPath + " " + strings.Join(fields, ",")
+}
+
+// IsHTTPSource reports whether a source name refers to an HTTP source
+func IsHTTPSource(source string) bool {
+	return strings.HasPrefix(source, httpSourcePrefix)
+}
+
+// HTTPSourceLabel returns the configured name of an HTTP source
+func HTTPSourceLabel(source string) string {
+	return strings.TrimPrefix(source, httpSourcePrefix)
+}
+
+// validateHTTPSources checks the HTTP sources of a config
+func validateHTTPSources(sources []HTTPSourceConfig) []ValidationError {
+	var errors []ValidationError
+	seen := make(map[string]bool)
+	for i, source := range sources {
+		name := strings.TrimSpace(source.Name)
+		switch {
+		case name == "":
+			errors = append(errors, ValidationError{
+				Field:   "HTTP",
+				Message: fmt.Sprintf("HTTP source #%d needs a name", i+1),
+				Index:   -1,
+			})
+		case seen[name]:
+			errors = append(errors, ValidationError{
+				Field:   "HTTP",
+				Message: fmt.Sprintf("duplicate HTTP source name '%s'", name),
+				Index:   -1,
+			})
+		}
+		seen[name] = true
+
+		url := strings.TrimSpace(source.URL)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			errors = append(errors, ValidationError{
+				Field:   "HTTP",
+				Message: fmt.Sprintf("HTTP source '%s' needs an http:// or https:// URL", name),
+				Index:   -1,
+			})
+		}
+		if source.TimeoutSeconds < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "HTTP",
+				Message: fmt.Sprintf("HTTP source '%s' timeout must not be negative", name),
+				Index:   -1,
+			})
+		}
+		for field := range source.Fields {
+			if !isHTTPMappableField(field) {
+				errors = append(errors, ValidationError{
+					Field:   "HTTP",
+					Message: fmt.Sprintf("HTTP source '%s' maps unknown field '%s' (valid: %s)", name, field, strings.Join(HTTPMappableFields, ", ")),
+					Index:   -1,
+				})
+			}
+		}
+	}
+	return errors
+}
+
+// isHTTPMappableField reports whether an HTTP source can map field
+func isHTTPMappableField(field string) bool {
+	for _, mappable := range HTTPMappableFields {
+		if field == mappable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package models
+
+// KnownHostsSource is the source name of hosts discovered in known_hosts
+const KnownHostsSource = "known-hosts"
+
+// KnownHostsConfig configures the discovery of hosts in a known_hosts file.
+// Discovery is off unless enabled.
+type KnownHostsConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Path        string `json:"path,omitempty"`        // Defaults to ~/.ssh/known_hosts
+	ScanHistory bool   `json:"scanHistory,omitempty"` // Also match hashed entries against ssh commands in the shell history
+}
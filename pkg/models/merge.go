@@ -0,0 +1,170 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MergeConfig controls how a host defined by several sources is combined
+type MergeConfig struct {
+	// Order lists sources from highest to lowest priority. An entry matches
+	// the source of that name and, for "ssh-config", every config file.
+	// Unlisted sources follow in their default order.
+	Order []string `json:"order,omitempty"`
+	// Strategy is MergeWinner (default) or MergeFieldMerge
+	Strategy string `json:"strategy,omitempty"`
+	// Fields overrides Strategy per host field (by JSON name). Besides the
+	// two strategies, a value may name the source to prefer for the field.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Merge strategies
+const (
+	MergeWinner     = "winner"      // Take every field from the highest priority source
+	MergeFieldMerge = "field-merge" // Take each field from the highest priority source that sets it
+)
+
+// MergedSource is the Variants key of a host combined from several sources
+const MergedSource = "merged"
+
+// FieldStrategy returns the strategy or preferred source for a field
+func (c MergeConfig) FieldStrategy(field string) string {
+	if strategy := c.Fields[field]; strategy != "" {
+		return strategy
+	}
+	if c.Strategy != "" {
+		return c.Strategy
+	}
+	return MergeWinner
+}
+
+// MergesFields reports whether any field is taken from other sources than
+// the winning one
+func (c MergeConfig) MergesFields() bool {
+	if c.Strategy == MergeFieldMerge {
+		return true
+	}
+	for _, strategy := range c.Fields {
+		if strategy != MergeWinner {
+			return true
+		}
+	}
+	return false
+}
+
+// Rank returns the position of source in Order, or -1 if it isn't listed
+func (c MergeConfig) Rank(source string) int {
+	for i, entry := range c.Order {
+		if SourceMatches(entry, source) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SourceMatches reports whether a source name from the config (e.g. in a
+// merge order) refers to source. A kind such as "ssh-config" matches all of
+// its sources ("ssh-config:<path>"), and "sshbuddy" is the manual source.
+func SourceMatches(entry, source string) bool {
+	entry = strings.TrimSpace(entry)
+	if entry == "sshbuddy" {
+		entry = "manual"
+	}
+	return entry == source || strings.HasPrefix(source, entry+":")
+}
+
+// mergeableFields maps the JSON names of mergeable host fields to their
+// struct field index. Identity and bookkeeping fields are excluded.
+var mergeableFields = func() map[string]int {
+	excluded := map[string]bool{
+		"alias": true, "source": true, "available_in": true, "favorite": true, "-": true,
+	}
+
+	fields := make(map[string]int)
+	t := reflect.TypeOf(Host{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && !excluded[name] {
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+// hostnameBoundFields say how to reach a hostname, so they are never merged
+// on their own but taken from the source that supplies the hostname. A
+// container's docker connect mode must not turn an SSH host into docker exec.
+var hostnameBoundFields = map[string]bool{"connect_mode": true, "ping_method": true}
+
+// FollowsHostname reports whether the named field is taken from the source
+// of the hostname rather than merged by its own strategy
+func FollowsHostname(field string) bool {
+	return hostnameBoundFields[field]
+}
+
+// MergeableFields returns the JSON names of the host fields that can be
+// merged from several sources
+func MergeableFields() []string {
+	names := make([]string, 0, len(mergeableFields))
+	t := reflect.TypeOf(Host{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if _, ok := mergeableFields[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FieldIsSet reports whether the named field has a non-empty value
+func (h *Host) FieldIsSet(field string) bool {
+	index, ok := mergeableFields[field]
+	if !ok {
+		return false
+	}
+	return !reflect.ValueOf(h).Elem().Field(index).IsZero()
+}
+
+// CopyField sets the named field to its value in from and reports whether
+// that changed h
+func (h *Host) CopyField(field string, from *Host) bool {
+	index, ok := mergeableFields[field]
+	if !ok {
+		return false
+	}
+	dst := reflect.ValueOf(h).Elem().Field(index)
+	src := reflect.ValueOf(from).Elem().Field(index)
+	if reflect.DeepEqual(dst.Interface(), src.Interface()) {
+		return false
+	}
+	dst.Set(src)
+	return true
+}
+
+// FieldString renders the named field for display. Lists are joined with
+// commas, options are written as Key=Value, and unset fields are empty.
+func (h *Host) FieldString(field string) string {
+	index, ok := mergeableFields[field]
+	if !ok {
+		return ""
+	}
+	switch v := reflect.ValueOf(h).Elem().Field(index).Interface().(type) {
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return ""
+	case []string:
+		return strings.Join(v, ", ")
+	case map[string]string:
+		var options []string
+		for _, key := range h.SortedOptionKeys() {
+			options = append(options, key+"="+v[key])
+		}
+		return strings.Join(options, "; ")
+	default:
+		return ""
+	}
+}
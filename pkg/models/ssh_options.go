@@ -0,0 +1,68 @@
+package models
+
+import "strings"
+
+// sshOptionKeywords lists the ssh_config(5) keywords accepted in Host.Options
+var sshOptionKeywords = []string{
+	"AddKeysToAgent", "AddressFamily", "BatchMode", "BindAddress", "BindInterface",
+	"CanonicalDomains", "CanonicalizeFallbackLocal", "CanonicalizeHostname",
+	"CanonicalizeMaxDots", "CanonicalizePermittedCNAMEs", "CASignatureAlgorithms",
+	"CertificateFile", "ChannelTimeout", "CheckHostIP", "Ciphers", "ClearAllForwardings",
+	"Compression", "ConnectionAttempts", "ConnectTimeout", "ControlMaster", "ControlPath",
+	"ControlPersist", "DynamicForward", "EnableEscapeCommandline", "EnableSSHKeysign",
+	"EscapeChar", "ExitOnForwardFailure", "FingerprintHash", "ForkAfterAuthentication",
+	"ForwardAgent", "ForwardX11", "ForwardX11Timeout", "ForwardX11Trusted",
+	"GatewayPorts", "GlobalKnownHostsFile", "GSSAPIAuthentication",
+	"GSSAPIDelegateCredentials", "HashKnownHosts", "HostbasedAcceptedAlgorithms",
+	"HostbasedAuthentication", "HostKeyAlgorithms", "HostKeyAlias", "HostName",
+	"IdentitiesOnly", "IdentityAgent", "IdentityFile", "IgnoreUnknown", "IPQoS",
+	"KbdInteractiveAuthentication", "KbdInteractiveDevices", "KexAlgorithms",
+	"KnownHostsCommand", "LocalCommand", "LocalForward", "LogLevel", "LogVerbose",
+	"MACs", "NoHostAuthenticationForLocalhost", "NumberOfPasswordPrompts",
+	"ObscureKeystrokeTiming", "PasswordAuthentication", "PermitLocalCommand",
+	"PermitRemoteOpen", "PKCS11Provider", "Port", "PreferredAuthentications",
+	"ProxyCommand", "ProxyJump", "ProxyUseFdpass", "PubkeyAcceptedAlgorithms",
+	"PubkeyAuthentication", "RekeyLimit", "RemoteCommand", "RemoteForward",
+	"RequestTTY", "RequiredRSASize", "RevokedHostKeys", "SecurityKeyProvider",
+	"SendEnv", "ServerAliveCountMax", "ServerAliveInterval", "SessionType", "SetEnv",
+	"StdinNull", "StreamLocalBindMask", "StreamLocalBindUnlink", "StrictHostKeyChecking",
+	"SyslogFacility", "Tag", "TCPKeepAlive", "Tunnel", "TunnelDevice", "UpdateHostKeys",
+	"User", "UserKnownHostsFile", "VerifyHostKeyDNS", "VisualHostKey", "XAuthLocation",
+}
+
+// optionField is a Host field that sets an ssh_config keyword
+type optionField struct {
+	label string // Label in the host form
+	json  string // Key in config.json
+}
+
+// optionFields lists the keywords Host has fields for. They are set through
+// those fields, not as options, so ssh doesn't get them twice.
+var optionFields = map[string]optionField{
+	"HostName":            {"Hostname", "hostname"},
+	"User":                {"User", "user"},
+	"Port":                {"Port", "port"},
+	"IdentityFile":        {"Identity File", "identity_file"},
+	"ProxyJump":           {"Proxy Jump", "proxy_jump"},
+	"LocalForward":        {"Local Forwards", "local_forwards"},
+	"RemoteForward":       {"Remote Forwards", "remote_forwards"},
+	"DynamicForward":      {"Dynamic Forwards", "dynamic_forwards"},
+	"ForwardAgent":        {"Forward Agent", "forward_agent"},
+	"ServerAliveInterval": {"Keepalive (sec)", "server_alive_interval"},
+}
+
+// sshOptionIndex maps lower-cased keywords to their canonical spelling
+var sshOptionIndex = func() map[string]string {
+	index := make(map[string]string, len(sshOptionKeywords))
+	for _, keyword := range sshOptionKeywords {
+		index[strings.ToLower(keyword)] = keyword
+	}
+	return index
+}()
+
+// CanonicalSSHOption returns the canonical spelling of an ssh_config keyword
+// and whether it is a known keyword. Keywords are matched case-insensitively.
+func CanonicalSSHOption(key string) (string, bool) {
+	canonical, ok := sshOptionIndex[strings.ToLower(strings.TrimSpace(key))]
+	return canonical, ok
+}
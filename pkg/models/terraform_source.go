@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TerraformSourceConfig configures a source that reads the instances of a
+// Terraform state
+type TerraformSourceConfig struct {
+	Name          string            `json:"name"` // Identifies the source as "terraform:<name>"
+	Enabled       bool              `json:"enabled"`
+	Path          string            `json:"path"`                    // terraform.tfstate, saved `terraform show -json` output, or a directory holding terraform.tfstate
+	ResourceTypes []string          `json:"resourceTypes,omitempty"` // Resource types read as hosts, default DefaultTerraformResourceTypes
+	Fields        map[string]string `json:"fields,omitempty"`        // JSONPath of each host field, relative to an instance's attributes
+	User          string            `json:"user,omitempty"`          // User of hosts without a mapped user
+}
+
+// DefaultTerraformResourceTypes lists the compute instance resources of
+// common providers
+var DefaultTerraformResourceTypes = []string{
+	"aws_instance",
+	"google_compute_instance",
+	"azurerm_linux_virtual_machine",
+	"azurerm_virtual_machine",
+	"digitalocean_droplet",
+	"hcloud_server",
+	"linode_instance",
+	"openstack_compute_instance_v2",
+	"vsphere_virtual_machine",
+	"libvirt_domain",
+}
+
+// terraformSourcePrefix starts the names of Terraform sources
+const terraformSourcePrefix = "terraform:"
+
+// SourceName returns the source name of the Terraform source
+func (c TerraformSourceConfig) SourceName() string {
+	return terraformSourcePrefix + c.Name
+}
+
+// Types returns the resource types read as hosts
+func (c TerraformSourceConfig) Types() []string {
+	if len(c.ResourceTypes) > 0 {
+		return c.ResourceTypes
+	}
+	return DefaultTerraformResourceTypes
+}
+
+// IsTerraformSource reports whether a source name refers to a Terraform source
+func IsTerraformSource(source string) bool {
+	return strings.HasPrefix(source, terraformSourcePrefix)
+}
+
+// TerraformSourceLabel returns the configured name of a Terraform source
+func TerraformSourceLabel(source string) string {
+	return strings.TrimPrefix(source, terraformSourcePrefix)
+}
+
+// validateTerraformSources checks the Terraform sources of a config
+func validateTerraformSources(sources []TerraformSourceConfig) []ValidationError {
+	var errors []ValidationError
+	seen := make(map[string]bool)
+	for i, source := range sources {
+		name := strings.TrimSpace(source.Name)
+		switch {
+		case name == "":
+			errors = append(errors, ValidationError{
+				Field:   "Terraform",
+				Message: fmt.Sprintf("terraform source #%d needs a name", i+1),
+				Index:   -1,
+			})
+		case seen[name]:
+			errors = append(errors, ValidationError{
+				Field:   "Terraform",
+				Message: fmt.Sprintf("duplicate terraform source name '%s'", name),
+				Index:   -1,
+			})
+		}
+		seen[name] = true
+
+		if strings.TrimSpace(source.Path) == "" {
+			errors = append(errors, ValidationError{
+				Field:   "Terraform",
+				Message: fmt.Sprintf("terraform source '%s' needs a state path", name),
+				Index:   -1,
+			})
+		}
+		// The same fields as for HTTP sources can be mapped
+		for field := range source.Fields {
+			if !isHTTPMappableField(field) {
+				errors = append(errors, ValidationError{
+					Field:   "Terraform",
+					Message: fmt.Sprintf("terraform source '%s' maps unknown field '%s' (valid: %s)", name, field, strings.Join(HTTPMappableFields, ", ")),
+					Index:   -1,
+				})
+			}
+		}
+	}
+	return errors
+}
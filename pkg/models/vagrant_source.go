@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VagrantSource is the source name of hosts read from Vagrant projects
+const VagrantSource = "vagrant"
+
+// VagrantSourceConfig configures the source that reads `vagrant ssh-config`
+// for the Vagrant projects in a list of directories
+type VagrantSourceConfig struct {
+	Enabled        bool     `json:"enabled"`
+	Dirs           []string `json:"dirs,omitempty"`           // Project directories, or directories containing projects
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"` // Per project, defaults to DefaultVagrantTimeoutSeconds
+}
+
+// DefaultVagrantTimeoutSeconds limits how long `vagrant ssh-config` may run
+// for one project. Vagrant is slow to start, so this is more than for exec
+// sources.
+const DefaultVagrantTimeoutSeconds = 30
+
+// Timeout returns how long `vagrant ssh-config` may run for one project
+func (c VagrantSourceConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultVagrantTimeoutSeconds * time.Second
+}
+
+// validate checks the Vagrant source settings
+func (c VagrantSourceConfig) validate() []ValidationError {
+	var errors []ValidationError
+	if c.Enabled {
+		hasDir := false
+		for _, dir := range c.Dirs {
+			if strings.TrimSpace(dir) != "" {
+				hasDir = true
+			}
+		}
+		if !hasDir {
+			errors = append(errors, ValidationError{
+				Field:   "Vagrant",
+				Message: "the Vagrant source needs at least one directory",
+				Index:   -1,
+			})
+		}
+	}
+	if c.TimeoutSeconds < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "Vagrant",
+			Message: fmt.Sprintf("Vagrant timeout must not be negative, got %d", c.TimeoutSeconds),
+			Index:   -1,
+		})
+	}
+	return errors
+}